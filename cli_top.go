@@ -0,0 +1,565 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/cli-runtime/pkg/printers"
+	"sigs.k8s.io/yaml"
+)
+
+// runTopCommand ist der Einstiegspunkt für "cluster-resources top <nodes|pods|namespaces>", modelliert
+// nach "kubectl top"/"karmadactl top". Jedes Subcommand hat sein eigenes flag.FlagSet, damit
+// --sort-by/--selector/--no-headers/-o pro Subcommand unabhängig vom globalen Server-/CLI-Modus
+// geparst werden.
+//
+// Parameter:
+// - args: Die Befehlszeilen-Argumente nach "top", also z.B. ["nodes", "--sort-by=cpu"].
+//
+// Rückgabewerte:
+//   - error: Ein Fehlerobjekt, falls kein oder ein unbekanntes Subcommand angegeben wurde, oder falls
+//     die Metrikberechnung fehlschlägt.
+func runTopCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("top: expected a subcommand, one of: nodes, pods, namespaces")
+	}
+
+	switch args[0] {
+	case "nodes":
+		return runTopNodes(args[1:])
+	case "pods":
+		return runTopPods(args[1:])
+	case "namespaces":
+		return runTopNamespaces(args[1:])
+	default:
+		return fmt.Errorf("top: unknown subcommand %q (expected one of: nodes, pods, namespaces)", args[0])
+	}
+}
+
+// topCommonFlags sind die Flags, die allen "top"-Subcommands gemeinsam sind.
+type topCommonFlags struct {
+	sortBy     *string
+	selector   *string
+	noHeaders  *bool
+	output     *string
+	nodeType   *string
+	kubeconfig *string
+}
+
+// addTopCommonFlags registriert die gemeinsamen Flags auf fs und gibt sie zum Auslesen nach fs.Parse
+// zurück.
+func addTopCommonFlags(fs *flag.FlagSet) *topCommonFlags {
+	return &topCommonFlags{
+		sortBy:     fs.String("sort-by", "", "Sort by cpu|memory|cpu%|memory% (default: by name)"),
+		selector:   fs.String("selector", "", "Label selector to filter results (e.g. 'node-role.kubernetes.io/worker=')"),
+		noHeaders:  fs.Bool("no-headers", false, "Don't print column headers"),
+		output:     fs.String("o", "table", "Output format: table|wide|json|yaml"),
+		nodeType:   fs.String("node-type", "all", "Filter by node type (worker, infra, master, all)"),
+		kubeconfig: fs.String("kubeconfig", os.Getenv("KUBECONFIG"), "Path to the kubeconfig file"),
+	}
+}
+
+// loadClusterMetricsForTop baut Kubernetes-/Metrik-Clients aus kubeconfig auf und berechnet die
+// ClusterMetrics für nodeType. Wird von allen drei top-Subcommands als gemeinsamer erster Schritt
+// verwendet.
+func loadClusterMetricsForTop(kubeconfig, nodeType string) (ClusterMetrics, error) {
+	clientset, metricsClient, err := getClients(&kubeconfig)
+	if err != nil {
+		return ClusterMetrics{}, fmt.Errorf("error creating Kubernetes clients: %v", err)
+	}
+	nodes, err := getNodes(clientset)
+	if err != nil {
+		return ClusterMetrics{}, fmt.Errorf("error listing nodes: %v", err)
+	}
+	clusterMetrics, err := calculateClusterMetrics(clientset, metricsClient, nodes, nodeType)
+	if err != nil {
+		return ClusterMetrics{}, fmt.Errorf("error calculating cluster metrics: %v", err)
+	}
+	return clusterMetrics, nil
+}
+
+// runTopNodes implementiert "cluster-resources top nodes", das Pendant zu "kubectl top nodes" mit
+// zusätzlicher Sortierung, Label-Selektor-Filterung und mehreren Ausgabeformaten.
+func runTopNodes(args []string) error {
+	fs := flag.NewFlagSet("top nodes", flag.ExitOnError)
+	common := addTopCommonFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	clusterMetrics, err := loadClusterMetricsForTop(*common.kubeconfig, *common.nodeType)
+	if err != nil {
+		return err
+	}
+
+	nodes, err := filterNodeMetricsBySelector(clusterMetrics.Nodes, *common.selector)
+	if err != nil {
+		return err
+	}
+	sortNodeMetricsBySortKey(nodes, *common.sortBy)
+
+	return printNodeMetrics(nodes, *common.output, *common.noHeaders)
+}
+
+// runTopPods implementiert "cluster-resources top pods", das Pendant zu "kubectl top pods" über alle
+// Namespaces hinweg. Mit --containers werden zusätzlich die einzelnen Container jedes Pods als eigene
+// Zeile ausgegeben, analog zu "kubectl top pods --containers".
+func runTopPods(args []string) error {
+	fs := flag.NewFlagSet("top pods", flag.ExitOnError)
+	common := addTopCommonFlags(fs)
+	containers := fs.Bool("containers", false, "Break down pod usage by container")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	clusterMetrics, err := loadClusterMetricsForTop(*common.kubeconfig, *common.nodeType)
+	if err != nil {
+		return err
+	}
+
+	var rows []podRow
+	if *containers {
+		rows, err = podContainerRows(clusterMetrics.Nodes, *common.selector)
+	} else {
+		rows, err = collectPodRows(clusterMetrics.Nodes, *common.selector)
+	}
+	if err != nil {
+		return err
+	}
+	sortPodRowsBySortKey(rows, *common.sortBy)
+
+	return printPodRows(rows, *common.output, *common.noHeaders, *containers)
+}
+
+// runTopNamespaces implementiert "cluster-resources top namespaces", das keine direkte kubectl-
+// Entsprechung hat, aber dieselbe Sortier-/Filter-/Ausgabe-UX bietet: die Pod-Metriken werden je
+// Namespace zu Requested/Limits/Used aufsummiert.
+func runTopNamespaces(args []string) error {
+	fs := flag.NewFlagSet("top namespaces", flag.ExitOnError)
+	common := addTopCommonFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	clusterMetrics, err := loadClusterMetricsForTop(*common.kubeconfig, *common.nodeType)
+	if err != nil {
+		return err
+	}
+
+	rows, err := collectPodRows(clusterMetrics.Nodes, *common.selector)
+	if err != nil {
+		return err
+	}
+	namespaces := aggregateNamespaceRows(rows)
+	sortNamespaceRowsBySortKey(namespaces, *common.sortBy)
+
+	return printNamespaceRows(namespaces, *common.output, *common.noHeaders)
+}
+
+// filterNodeMetricsBySelector gibt die Teilmenge von nodes zurück, deren Labels dem Selektor
+// entsprechen. Ein leerer Selektor lässt alle Nodes durch.
+func filterNodeMetricsBySelector(nodes []NodeMetrics, selector string) ([]NodeMetrics, error) {
+	if selector == "" {
+		return nodes, nil
+	}
+	sel, err := labels.Parse(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --selector %q: %v", selector, err)
+	}
+	var filtered []NodeMetrics
+	for _, node := range nodes {
+		if sel.Matches(labels.Set(node.Labels)) {
+			filtered = append(filtered, node)
+		}
+	}
+	return filtered, nil
+}
+
+// podRow ist eine denormalisierte Zeile für "top pods"/"top namespaces": ein Pod (und optional dessen
+// Container), zusammen mit dem Namen des Nodes, auf dem er läuft. Die Node-Labels für --selector
+// werden schon beim Sammeln der Zeilen ausgewertet und sind nicht Teil von podRow.
+type podRow struct {
+	Namespace string
+	Pod       string
+	Node      string
+	Container string // leer für die Pod-Summenzeile
+	RequestedCPU,
+	RequestedMemory,
+	LimitsCPU,
+	LimitsMemory,
+	UsedCPU,
+	UsedMemory string
+}
+
+// collectPodRows sammelt aus allen Nodes die darauf laufenden Pods zu einer flachen Liste von
+// podRow-Summenzeilen (eine Zeile pro Pod) und filtert sie nach dem Node-Label-Selektor.
+func collectPodRows(nodes []NodeMetrics, selector string) ([]podRow, error) {
+	var sel labels.Selector
+	if selector != "" {
+		parsed, err := labels.Parse(selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --selector %q: %v", selector, err)
+		}
+		sel = parsed
+	}
+
+	var rows []podRow
+	for _, node := range nodes {
+		if sel != nil && !sel.Matches(labels.Set(node.Labels)) {
+			continue
+		}
+		for _, pod := range node.Pods {
+			var reqCPU, reqMem, limCPU, limMem, usedCPU, usedMem resource.Quantity
+			for _, c := range pod.Containers {
+				reqCPU.Add(quantityOrZero(c.RequestedCPU))
+				reqMem.Add(quantityOrZero(c.RequestedMemory))
+				limCPU.Add(quantityOrZero(c.LimitsCPU))
+				limMem.Add(quantityOrZero(c.LimitsMemory))
+				usedCPU.Add(quantityOrZero(c.UsedCPU))
+				usedMem.Add(quantityOrZero(c.UsedMemory))
+			}
+			rows = append(rows, podRow{
+				Namespace:       pod.Namespace,
+				Pod:             pod.Name,
+				Node:            node.Name,
+				RequestedCPU:    convertCpuStr(reqCPU),
+				RequestedMemory: convertMemStr(reqMem),
+				LimitsCPU:       convertCpuStr(limCPU),
+				LimitsMemory:    convertMemStr(limMem),
+				UsedCPU:         convertCpuStr(usedCPU),
+				UsedMemory:      convertMemStr(usedMem),
+			})
+		}
+	}
+	return rows, nil
+}
+
+// podContainerRows baut aus den Pods aller Nodes eine Zeile je Container, für "top pods --containers",
+// und filtert sie wie collectPodRows nach dem Node-Label-Selektor.
+func podContainerRows(nodes []NodeMetrics, selector string) ([]podRow, error) {
+	var sel labels.Selector
+	if selector != "" {
+		parsed, err := labels.Parse(selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --selector %q: %v", selector, err)
+		}
+		sel = parsed
+	}
+
+	var rows []podRow
+	for _, node := range nodes {
+		if sel != nil && !sel.Matches(labels.Set(node.Labels)) {
+			continue
+		}
+		for _, pod := range node.Pods {
+			for _, c := range pod.Containers {
+				rows = append(rows, podRow{
+					Namespace:       pod.Namespace,
+					Pod:             pod.Name,
+					Node:            node.Name,
+					Container:       c.Name,
+					RequestedCPU:    c.RequestedCPU,
+					RequestedMemory: c.RequestedMemory,
+					LimitsCPU:       c.LimitsCPU,
+					LimitsMemory:    c.LimitsMemory,
+					UsedCPU:         c.UsedCPU,
+					UsedMemory:      c.UsedMemory,
+				})
+			}
+		}
+	}
+	return rows, nil
+}
+
+// namespaceRow ist eine über alle Pods eines Namespaces aufsummierte Zeile für "top namespaces".
+type namespaceRow struct {
+	Namespace string
+	Pods      int
+	RequestedCPU,
+	RequestedMemory,
+	LimitsCPU,
+	LimitsMemory,
+	UsedCPU,
+	UsedMemory string
+}
+
+// aggregateNamespaceRows summiert die podRow-Werte je Namespace zu einer namespaceRow.
+func aggregateNamespaceRows(rows []podRow) []namespaceRow {
+	type totals struct {
+		pods                                             int
+		reqCPU, reqMem, limCPU, limMem, usedCPU, usedMem resource.Quantity
+	}
+	byNamespace := make(map[string]*totals)
+	var order []string
+	for _, row := range rows {
+		t, ok := byNamespace[row.Namespace]
+		if !ok {
+			t = &totals{}
+			byNamespace[row.Namespace] = t
+			order = append(order, row.Namespace)
+		}
+		t.pods++
+		t.reqCPU.Add(quantityOrZero(row.RequestedCPU))
+		t.reqMem.Add(quantityOrZero(row.RequestedMemory))
+		t.limCPU.Add(quantityOrZero(row.LimitsCPU))
+		t.limMem.Add(quantityOrZero(row.LimitsMemory))
+		t.usedCPU.Add(quantityOrZero(row.UsedCPU))
+		t.usedMem.Add(quantityOrZero(row.UsedMemory))
+	}
+
+	rowsOut := make([]namespaceRow, 0, len(order))
+	for _, ns := range order {
+		t := byNamespace[ns]
+		rowsOut = append(rowsOut, namespaceRow{
+			Namespace:       ns,
+			Pods:            t.pods,
+			RequestedCPU:    convertCpuStr(t.reqCPU),
+			RequestedMemory: convertMemStr(t.reqMem),
+			LimitsCPU:       convertCpuStr(t.limCPU),
+			LimitsMemory:    convertMemStr(t.limMem),
+			UsedCPU:         convertCpuStr(t.usedCPU),
+			UsedMemory:      convertMemStr(t.usedMem),
+		})
+	}
+	return rowsOut
+}
+
+// quantityOrZero parst s als resource.Quantity und gibt bei einem Parse-Fehler (z.B. weil s "n/a" ist,
+// solange metrics.k8s.io nicht erreichbar war) eine Null-Quantity statt eines Fehlers zurück, damit
+// Sortierung und Aggregation nicht an einzelnen fehlenden Samples scheitern.
+func quantityOrZero(s string) resource.Quantity {
+	q, err := resource.ParseQuantity(s)
+	if err != nil {
+		return resource.Quantity{}
+	}
+	return q
+}
+
+// sortKeyValue liest den durch --sort-by ausgewählten Wert (cpu, memory, cpu%, memory%) aus einer der
+// drei Zeilenarten. Percent-Strings wie "45.3%" werden dabei als float geparst; "n/a" sortiert ans Ende.
+func sortKeyValue(sortBy, cpu, mem, cpuPercent, memPercent string) (float64, bool) {
+	switch strings.ToLower(sortBy) {
+	case "cpu":
+		q := quantityOrZero(cpu)
+		return q.AsApproximateFloat64(), true
+	case "memory", "mem":
+		q := quantityOrZero(mem)
+		return q.AsApproximateFloat64(), true
+	case "cpu%", "cpu-percent":
+		return percentOrZero(cpuPercent), true
+	case "memory%", "mem%", "memory-percent":
+		return percentOrZero(memPercent), true
+	default:
+		return 0, false
+	}
+}
+
+// percentOrZero parst einen Prozentstring wie "45.3%" als float64; gibt bei "n/a" oder einem
+// Parse-Fehler 0 zurück.
+func percentOrZero(s string) float64 {
+	value, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+// sortNodeMetricsBySortKey sortiert nodes gemäß --sort-by absteigend; ist sortBy leer oder unbekannt,
+// wird stattdessen alphabetisch nach Namen sortiert (das bisherige Verhalten).
+func sortNodeMetricsBySortKey(nodes []NodeMetrics, sortBy string) {
+	if sortBy == "" {
+		sortNodeMetricsByName(nodes)
+		return
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		vi, ok := sortKeyValue(sortBy, nodes[i].UsedCPU, nodes[i].UsedMemory, nodes[i].CPUUtilCapacity, nodes[i].MemUtilCapacity)
+		vj, _ := sortKeyValue(sortBy, nodes[j].UsedCPU, nodes[j].UsedMemory, nodes[j].CPUUtilCapacity, nodes[j].MemUtilCapacity)
+		if !ok {
+			return nodes[i].Name < nodes[j].Name
+		}
+		return vi > vj
+	})
+}
+
+// sortPodRowsBySortKey sortiert rows gemäß --sort-by absteigend; ist sortBy leer oder unbekannt, wird
+// stattdessen nach Namespace/Pod sortiert.
+func sortPodRowsBySortKey(rows []podRow, sortBy string) {
+	if sortBy == "" {
+		sort.SliceStable(rows, func(i, j int) bool {
+			if rows[i].Namespace != rows[j].Namespace {
+				return rows[i].Namespace < rows[j].Namespace
+			}
+			return rows[i].Pod < rows[j].Pod
+		})
+		return
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		vi, ok := sortKeyValue(sortBy, rows[i].UsedCPU, rows[i].UsedMemory, "", "")
+		vj, _ := sortKeyValue(sortBy, rows[j].UsedCPU, rows[j].UsedMemory, "", "")
+		if !ok {
+			if rows[i].Namespace != rows[j].Namespace {
+				return rows[i].Namespace < rows[j].Namespace
+			}
+			return rows[i].Pod < rows[j].Pod
+		}
+		return vi > vj
+	})
+}
+
+// sortNamespaceRowsBySortKey sortiert rows gemäß --sort-by absteigend; ist sortBy leer oder unbekannt,
+// wird stattdessen alphabetisch nach Namespace sortiert.
+func sortNamespaceRowsBySortKey(rows []namespaceRow, sortBy string) {
+	if sortBy == "" {
+		sort.SliceStable(rows, func(i, j int) bool { return rows[i].Namespace < rows[j].Namespace })
+		return
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		vi, ok := sortKeyValue(sortBy, rows[i].UsedCPU, rows[i].UsedMemory, "", "")
+		vj, _ := sortKeyValue(sortBy, rows[j].UsedCPU, rows[j].UsedMemory, "", "")
+		if !ok {
+			return rows[i].Namespace < rows[j].Namespace
+		}
+		return vi > vj
+	})
+}
+
+// printNodeMetrics gibt nodes im angeforderten Format aus. table/wide nutzen den cli-runtime
+// TablePrinter, json/yaml marshalen die Struktur direkt.
+func printNodeMetrics(nodes []NodeMetrics, output string, noHeaders bool) error {
+	switch strings.ToLower(output) {
+	case "json":
+		return printJSON(nodes)
+	case "yaml":
+		return printYAML(nodes)
+	case "table", "wide", "":
+		wide := strings.ToLower(output) == "wide"
+		table := &metav1.Table{
+			ColumnDefinitions: []metav1.TableColumnDefinition{
+				{Name: "Name", Type: "string"},
+				{Name: "Type", Type: "string"},
+				{Name: "CPU(cores)", Type: "string"},
+				{Name: "CPU%", Type: "string"},
+				{Name: "Memory", Type: "string"},
+				{Name: "Memory%", Type: "string"},
+				{Name: "CPU Requests", Type: "string", Priority: 1},
+				{Name: "CPU Limits", Type: "string", Priority: 1},
+				{Name: "Memory Requests", Type: "string", Priority: 1},
+				{Name: "Memory Limits", Type: "string", Priority: 1},
+			},
+		}
+		for _, node := range nodes {
+			cells := []interface{}{
+				node.Name, node.NodeType, node.UsedCPU, node.CPUUtilCapacity, node.UsedMemory, node.MemUtilCapacity,
+				node.RequestedCPU, node.LimitsCPU, node.RequestedMemory, node.LimitsMemory,
+			}
+			table.Rows = append(table.Rows, metav1.TableRow{Cells: cells})
+		}
+		return printers.NewTablePrinter(printers.PrintOptions{NoHeaders: noHeaders, Wide: wide}).PrintObj(table, os.Stdout)
+	default:
+		return fmt.Errorf("unsupported -o value %q (expected table, wide, json or yaml)", output)
+	}
+}
+
+// printPodRows gibt rows im angeforderten Format aus. Mit containers=true erwartet sie Container-Zeilen
+// aus podContainerRows (statt der pro-Pod aggregierten Zeilen aus collectPodRows) und gibt in der
+// Tabelle zusätzlich die Container-Spalte aus.
+func printPodRows(rows []podRow, output string, noHeaders, containers bool) error {
+	switch strings.ToLower(output) {
+	case "json":
+		return printJSON(rows)
+	case "yaml":
+		return printYAML(rows)
+	case "table", "wide", "":
+		table := &metav1.Table{ColumnDefinitions: podTableColumns(containers)}
+		for _, row := range rows {
+			cells := []interface{}{row.Namespace, row.Pod}
+			if containers {
+				cells = append(cells, row.Container)
+			}
+			cells = append(cells, row.UsedCPU, row.UsedMemory, row.Node)
+			table.Rows = append(table.Rows, metav1.TableRow{Cells: cells})
+		}
+		wide := strings.ToLower(output) == "wide"
+		return printers.NewTablePrinter(printers.PrintOptions{NoHeaders: noHeaders, Wide: wide}).PrintObj(table, os.Stdout)
+	default:
+		return fmt.Errorf("unsupported -o value %q (expected table, wide, json or yaml)", output)
+	}
+}
+
+// podTableColumns baut die Spaltendefinitionen für "top pods", mit einer zusätzlichen Container-Spalte
+// wenn --containers gesetzt ist.
+func podTableColumns(containers bool) []metav1.TableColumnDefinition {
+	cols := []metav1.TableColumnDefinition{
+		{Name: "Namespace", Type: "string"},
+		{Name: "Pod", Type: "string"},
+	}
+	if containers {
+		cols = append(cols, metav1.TableColumnDefinition{Name: "Container", Type: "string"})
+	}
+	return append(cols,
+		metav1.TableColumnDefinition{Name: "CPU(cores)", Type: "string"},
+		metav1.TableColumnDefinition{Name: "Memory", Type: "string"},
+		metav1.TableColumnDefinition{Name: "Node", Type: "string"},
+	)
+}
+
+// printNamespaceRows gibt rows im angeforderten Format aus.
+func printNamespaceRows(rows []namespaceRow, output string, noHeaders bool) error {
+	switch strings.ToLower(output) {
+	case "json":
+		return printJSON(rows)
+	case "yaml":
+		return printYAML(rows)
+	case "table", "wide", "":
+		table := &metav1.Table{
+			ColumnDefinitions: []metav1.TableColumnDefinition{
+				{Name: "Namespace", Type: "string"},
+				{Name: "Pods", Type: "integer"},
+				{Name: "CPU(cores)", Type: "string"},
+				{Name: "Memory", Type: "string"},
+				{Name: "CPU Requests", Type: "string", Priority: 1},
+				{Name: "CPU Limits", Type: "string", Priority: 1},
+				{Name: "Memory Requests", Type: "string", Priority: 1},
+				{Name: "Memory Limits", Type: "string", Priority: 1},
+			},
+		}
+		for _, row := range rows {
+			table.Rows = append(table.Rows, metav1.TableRow{Cells: []interface{}{
+				row.Namespace, row.Pods, row.UsedCPU, row.UsedMemory,
+				row.RequestedCPU, row.LimitsCPU, row.RequestedMemory, row.LimitsMemory,
+			}})
+		}
+		wide := strings.ToLower(output) == "wide"
+		return printers.NewTablePrinter(printers.PrintOptions{NoHeaders: noHeaders, Wide: wide}).PrintObj(table, os.Stdout)
+	default:
+		return fmt.Errorf("unsupported -o value %q (expected table, wide, json or yaml)", output)
+	}
+}
+
+// printJSON schreibt v eingerückt als JSON nach stdout.
+func printJSON(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling JSON: %v", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// printYAML schreibt v als YAML nach stdout.
+func printYAML(v interface{}) error {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("error marshaling YAML: %v", err)
+	}
+	fmt.Print(string(data))
+	return nil
+}
@@ -10,19 +10,24 @@ import (
 	"net/http"
 	"os"
 	"sort"
-	"strconv"
 	"strings"
 	"sync"
 	"text/tabwriter"
+	"time"
 
-	"github.com/tealeg/xlsx"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	metricsapi "k8s.io/metrics/pkg/apis/metrics/v1beta1"
 	metricsv "k8s.io/metrics/pkg/client/clientset/versioned"
+
+	"github.com/jaegdi/cluster-resources/internal/cache"
+	"github.com/jaegdi/cluster-resources/internal/history"
 )
 
 // NodeMetrics enthält Metriken für einen einzelnen Knoten.
@@ -41,6 +46,74 @@ type NodeMetrics struct {
 	UsedCPU         string            // Die genutzte CPU des Knotens
 	UsedMemory      string            // Der genutzte Speicher des Knotens
 	Labels          map[string]string // Neues Feld für Labels
+
+	// Rohwerte der obigen CPU/Memory-Felder als resource.Quantity, damit Presentation-Code (z.B. der
+	// Excel-Export in buildClusterMetricsWorkbook) numerisch damit rechnen kann, ohne die formatierten
+	// Strings (z.B. "4Gi") wieder zurückparsen zu müssen. UsedCPUQuantity/UsedMemoryQuantity sind nur
+	// aussagekräftig, wenn MetricsAvailable true ist (sonst UsedCPU/UsedMemory == "n/a").
+	PhysicalCPUQuantity     resource.Quantity
+	RequestedCPUQuantity    resource.Quantity
+	LimitsCPUQuantity       resource.Quantity
+	UsedCPUQuantity         resource.Quantity
+	PhysicalMemoryQuantity  resource.Quantity
+	RequestedMemoryQuantity resource.Quantity
+	LimitsMemoryQuantity    resource.Quantity
+	UsedMemoryQuantity      resource.Quantity
+	MetricsAvailable        bool // ob für diesen Knoten ein metrics-server-Sample vorlag (siehe UsedCPU/UsedMemory)
+
+	// Utilization-Verhältnisse (genutzt/Basis), analog zu den kubeletstats-Metriken
+	// k8s.container.cpu.node.utilization und k8s.container.memory.node.utilization.
+	// *UtilLimits ist "n/a", wenn für den Knoten keine Limits gesetzt sind (Division durch 0 vermieden).
+	CPUUtilCapacity string // genutzte CPU / physische CPU-Kapazität
+	CPUUtilRequests string // genutzte CPU / angeforderte CPU
+	CPUUtilLimits   string // genutzte CPU / CPU-Limits, "n/a" falls keine Limits gesetzt sind
+	MemUtilCapacity string // genutzter Speicher / physischer Speicher
+	MemUtilRequests string // genutzter Speicher / angeforderter Speicher
+	MemUtilLimits   string // genutzter Speicher / Speicher-Limits, "n/a" falls keine Limits gesetzt sind
+
+	// Ephemeral-Storage-Metriken, analog zu den CPU/Memory-Feldern oben. UsedEphemeralStorage ist
+	// "n/a", weil metrics.k8s.io in der Praxis keine ephemeral-storage-Nutzung je Node/Pod liefert
+	// (nur die kubelet-Summary-API tut das, die dieses Tool nicht abfragt).
+	EphemeralStorageCapacity     string // Ephemeral-Storage-Kapazität des Knotens
+	EphemeralStorageRequests     string // Angeforderter Ephemeral-Storage aller Pods auf dem Knoten
+	EphemeralStorageLimits       string // Ephemeral-Storage-Limits aller Pods auf dem Knoten
+	UsedEphemeralStorage         string // Genutzter Ephemeral-Storage, "n/a" falls nicht verfügbar
+	EphemeralStorageUtilCapacity string // genutzter Ephemeral-Storage / Kapazität, "n/a" falls nicht verfügbar
+
+	// Rohwerte der obigen Ephemeral-Storage-Felder als resource.Quantity, analog zu *CPUQuantity/*MemoryQuantity.
+	EphemeralStorageCapacityQuantity resource.Quantity
+	EphemeralStorageRequestsQuantity resource.Quantity
+	EphemeralStorageLimitsQuantity   resource.Quantity
+
+	Pods []PodMetrics // Hierarchische Node→Pod→Container-Sicht auf die Ressourcennutzung
+}
+
+// PodMetrics enthält die Ressourcen- und Nutzungsdaten eines einzelnen Pods auf einem Knoten.
+type PodMetrics struct {
+	Name       string             // Der Name des Pods
+	Namespace  string             // Der Namespace des Pods
+	Containers []ContainerMetrics // Die Container des Pods
+}
+
+// ContainerMetrics enthält die angeforderten, begrenzten und genutzten Ressourcen eines einzelnen
+// Containers sowie die daraus abgeleiteten Utilization-Verhältnisse, analog zu
+// k8s.pod.cpu.limit_utilization / k8s.pod.memory.limit_utilization.
+type ContainerMetrics struct {
+	Name            string // Der Name des Containers
+	RequestedCPU    string // Die angeforderte CPU des Containers
+	RequestedMemory string // Der angeforderte Speicher des Containers
+	LimitsCPU       string // Die begrenzte CPU des Containers
+	LimitsMemory    string // Der begrenzte Speicher des Containers
+	UsedCPU         string // Die genutzte CPU des Containers
+	UsedMemory      string // Der genutzte Speicher des Containers
+
+	CPUUtilRequests string // genutzte CPU / angeforderte CPU
+	CPUUtilLimits   string // genutzte CPU / CPU-Limits, "n/a" falls keine Limits gesetzt sind
+	MemUtilRequests string // genutzter Speicher / angeforderter Speicher
+	MemUtilLimits   string // genutzter Speicher / Speicher-Limits, "n/a" falls keine Limits gesetzt sind
+
+	EphemeralStorageRequests string // Angeforderter Ephemeral-Storage des Containers
+	EphemeralStorageLimits   string // Ephemeral-Storage-Limits des Containers
 }
 
 // ClusterMetrics enthält aggregierte Metriken für den gesamten Cluster.
@@ -57,21 +130,88 @@ type ClusterMetrics struct {
 	TotalLimitsMemory    string        // Die Gesamtsumme des begrenzten Speichers im Cluster
 	TotalUsedCPU         string        // Die Gesamtsumme der genutzten CPU im Cluster
 	TotalUsedMemory      string        // Die Gesamtsumme des genutzten Speichers im Cluster
+
+	// Rohwerte der obigen Total*-Felder als resource.Quantity, analog zu NodeMetrics.*Quantity.
+	TotalPhysicalCPUQuantity     resource.Quantity
+	TotalPhysicalMemoryQuantity  resource.Quantity
+	TotalRequestedCPUQuantity    resource.Quantity
+	TotalRequestedMemoryQuantity resource.Quantity
+	TotalLimitsCPUQuantity       resource.Quantity
+	TotalLimitsMemoryQuantity    resource.Quantity
+	TotalUsedCPUQuantity         resource.Quantity
+	TotalUsedMemoryQuantity      resource.Quantity
+
+	TotalEphemeralStorageCapacity string // Die Gesamtsumme der Ephemeral-Storage-Kapazität im Cluster
+	TotalEphemeralStorageRequests string // Die Gesamtsumme des angeforderten Ephemeral-Storage im Cluster
+	TotalEphemeralStorageLimits   string // Die Gesamtsumme der Ephemeral-Storage-Limits im Cluster
+
+	// Rohwerte der obigen TotalEphemeralStorage*-Felder als resource.Quantity, analog zu TotalPhysicalCPUQuantity usw.
+	TotalEphemeralStorageCapacityQuantity resource.Quantity
+	TotalEphemeralStorageRequestsQuantity resource.Quantity
+	TotalEphemeralStorageLimitsQuantity   resource.Quantity
 }
 
 var nodeType, serviceaccountname, kubeconfig *string // Globale Variablen für den Knotentyp und den Service-Account
 var serverMode *bool                                 // Globale Variable für den Servermodus
+var scrapeInterval *time.Duration                    // Globale Variable für das Prometheus-Scrape-Intervall
+var useWatchCache *bool                              // Globale Variable: Informer-Cache statt Polling pro Request verwenden
+var resyncPeriod *time.Duration                      // Globale Variable für den Resync-Zeitraum des Informer-Caches
+var metricsPollInterval *time.Duration               // Globale Variable für das Poll-Intervall der metrics.k8s.io-API im Cache
+var tokenAudience *string                            // Globale Variable: Audience für das TokenRequest-basierte Service-Account-Token
+var historySampleInterval *time.Duration             // Globale Variable: Abtastintervall für den /metrics/history-Sampler
+var historyCheckpointPath *string                    // Globale Variable: Pfad für /metrics/history-Checkpoint-Dateien, leer deaktiviert Checkpointing
+var historyCheckpointInterval *time.Duration         // Globale Variable: Intervall, in dem der /metrics/history-Store auf Disk geschrieben wird
+var workloadNamespaces *string                       // Globale Variable: Kommagetrennte Liste der für /metrics/namespaces und /metrics/workloads zu durchsuchenden Namespaces, leer bedeutet alle Namespaces
 // Initialize the cluster metrics struct
 var clusterMetrics ClusterMetrics
 
+// includeOptions steuert, welche optionalen Spaltengruppen renderTemplate und printASCIITable
+// zusätzlich zu den CPU/Memory-Basisspalten anzeigen. Sie wird aus dem "include"-Query-Parameter
+// des /metrics-Endpunkts geparst (z.B. "?include=ephemeral").
+type includeOptions struct {
+	Ephemeral bool // Ephemeral-Storage-Spalten (Capacity/Requests/Limits/Used/Util)
+}
+
+// parseIncludeOptions liest den "include"-Query-Parameter (kommagetrennt, z.B. "ephemeral")
+// und gibt die daraus resultierenden includeOptions zurück. Unbekannte Werte werden ignoriert.
+func parseIncludeOptions(r *http.Request) includeOptions {
+	var opts includeOptions
+	for _, part := range strings.Split(r.URL.Query().Get("include"), ",") {
+		switch strings.TrimSpace(part) {
+		case "ephemeral":
+			opts.Ephemeral = true
+		}
+	}
+	return opts
+}
+
+// templateData bündelt die ClusterMetrics mit den includeOptions für renderTemplate, damit die
+// HTML-Vorlage sowohl auf die Metrikfelder (via Einbettung, z.B. {{ .Nodes }}) als auch auf
+// {{ .Include.Ephemeral }} zugreifen kann.
+type templateData struct {
+	ClusterMetrics
+	Include includeOptions
+}
+
 // main ist der Einstiegspunkt der Anwendung.
 //
-// Diese Funktion parst die Befehlszeilen-Flags und entscheidet, ob die Anwendung im Servermodus oder im CLI-Modus ausgeführt wird.
+// Diese Funktion parst die Befehlszeilen-Flags und entscheidet, ob die Anwendung im Servermodus, im
+// "top"-Subcommand-Modus (kubectl-top-artig, siehe cli_top.go) oder im alten CLI-Modus ausgeführt wird.
 // Im Servermodus wird ein HTTP-Server gestartet, der Metriken für Knoten im Kubernetes-Cluster sammelt und anzeigt.
 // Im CLI-Modus werden die Metriken direkt in der Konsole angezeigt.
 func main() {
+	// "cluster-resources top ..." wird vor dem globalen flag.Parse() abgefangen, weil die
+	// top-Subcommands ihr eigenes FlagSet mit eigenen Flags (--sort-by, --selector, ...) verwenden.
+	if len(os.Args) > 1 && os.Args[1] == "top" {
+		if err := runTopCommand(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Parse command-line flags
-	nodeType, serverMode, serviceaccountname, kubeconfig = getFlags()
+	nodeType, serverMode, serviceaccountname, kubeconfig, scrapeInterval, useWatchCache, resyncPeriod, metricsPollInterval, tokenAudience, historySampleInterval, historyCheckpointPath, historyCheckpointInterval, workloadNamespaces = getFlags()
 	log.Println("\nKnotentyp: ", *nodeType, "\nServermodus: ", *serverMode, "\nService-Account: ", *serviceaccountname, "\nKubeconfig: ", *kubeconfig)
 
 	// Check if running in server mode
@@ -81,9 +221,10 @@ func main() {
 		var metricsClient *metricsv.Clientset
 
 		// Check if running in a Kubernetes Pod
+		var clientErr error
 		if _, err := os.Stat("/var/run/secrets/kubernetes.io/serviceaccount/token"); err == nil {
 			// Running in a Pod, use in-cluster configuration
-			clientset, metricsClient = getPodClients()
+			clientset, metricsClient, clientErr = getPodClients()
 		} else {
 			// Not running in a Pod, use kubeconfig
 			if *kubeconfig == "" {
@@ -92,40 +233,141 @@ func main() {
 					log.Fatalf("kubeconfig not provided and KUBECONFIG environment variable is not set")
 				}
 			}
-			clientset, metricsClient = getClients(kubeconfig)
+			clientset, metricsClient, clientErr = getClients(kubeconfig)
+		}
+		if clientErr != nil {
+			log.Fatalf("Error creating Kubernetes clients: %v", clientErr)
 		}
 
-		// Get the list of nodes in the cluster
-		nodes := getNodes(clientset)
 		fmt.Fprintln(os.Stderr, "Servermodus -- Knotentyp: ", *nodeType, "sammelt Metriken für Knoten")
 
-		// HTTP-Handler für das /metrics-Endpunkt einrichten
-		http.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
-			nodeType := r.URL.Query().Get("node-type")
-			if nodeType == "" {
-				nodeType = "all"
+		// snapshot liefert eine ClusterMetrics-Momentaufnahme für den /metrics/history-Sampler
+		// (siehe history_handler.go). Wird unten befüllt, je nachdem ob --use-watch-cache gesetzt ist,
+		// damit der Sampler selbst nichts vom Watch-Cache-/Polling-Unterschied wissen muss.
+		var snapshot func() (ClusterMetrics, error)
+
+		if *useWatchCache {
+			// Informer/Watch-basierter Cache: ein Request liest nur noch aus lokal gehaltenen
+			// Stores statt bei jedem Scrape Nodes().List/Pods("").List gegen die API auszuführen.
+			clusterCache := cache.NewClusterCache(clientset, metricsClient, *resyncPeriod, *metricsPollInterval)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if err := clusterCache.Start(ctx); err != nil {
+				log.Fatalf("Error starting cluster cache: %v", err)
+			}
+
+			snapshot = func() (ClusterMetrics, error) {
+				return calculateClusterMetricsFromCache(clusterCache, *nodeType)
+			}
+
+			http.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+				nodeType := r.URL.Query().Get("node-type")
+				if nodeType == "" {
+					nodeType = "all"
+				}
+				var err error
+				clusterMetrics, err = calculateClusterMetricsFromCache(clusterCache, nodeType)
+				if err != nil {
+					log.Printf("Error calculating cluster metrics from cache: %v", err)
+					http.Error(w, "Error calculating cluster metrics", http.StatusInternalServerError)
+					return
+				}
+				sortNodeMetricsByName(clusterMetrics.Nodes)
+				include := parseIncludeOptions(r)
+				serveClusterMetrics(w, r, clusterMetrics, include)
+			})
+		} else {
+			snapshot = func() (ClusterMetrics, error) {
+				nodes, err := getNodes(clientset)
+				if err != nil {
+					return ClusterMetrics{}, err
+				}
+				return calculateClusterMetrics(clientset, metricsClient, nodes, *nodeType)
 			}
-			clusterMetrics = calculateClusterMetrics(clientset, metricsClient, nodes, nodeType)
-			sortNodeMetricsByName(clusterMetrics.Nodes)
-			renderTemplate(w, clusterMetrics)
-			printASCIITable(clusterMetrics)
-		})
 
-		// HTTP-Handler für das /download/excel-Endpunkt einrichten
-		http.HandleFunc("/download/excel", downloadExcelHandler)
+			// HTTP-Handler für das /metrics-Endpunkt einrichten
+			http.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+				nodeType := r.URL.Query().Get("node-type")
+				if nodeType == "" {
+					nodeType = "all"
+				}
+				// Get the list of nodes in the cluster. Wird pro Request frisch geholt,
+				// damit ein transienter API-Fehler nur diesen Request betrifft statt den
+				// ganzen Server zu beenden.
+				nodes, err := getNodes(clientset)
+				if err != nil {
+					log.Printf("Error listing nodes: %v", err)
+					http.Error(w, "Error listing nodes", http.StatusInternalServerError)
+					return
+				}
+				clusterMetrics, err = calculateClusterMetrics(clientset, metricsClient, nodes, nodeType)
+				if err != nil {
+					log.Printf("Error calculating cluster metrics: %v", err)
+					http.Error(w, "Error calculating cluster metrics", http.StatusInternalServerError)
+					return
+				}
+				sortNodeMetricsByName(clusterMetrics.Nodes)
+				include := parseIncludeOptions(r)
+				serveClusterMetrics(w, r, clusterMetrics, include)
+			})
+		}
+
+		// Zeitreihen-Store für /metrics/history: ein Sampler läuft im Hintergrund und schreibt alle
+		// --history-sample-interval eine Momentaufnahme in den Store; ein Checkpoint wird nur
+		// geschrieben, wenn --history-checkpoint-path gesetzt ist.
+		if err := history.EnsureCheckpointDir(*historyCheckpointPath); err != nil {
+			log.Printf("history: failed to create checkpoint directory, checkpointing may fail: %v", err)
+		}
+		historyStore := history.NewStore(history.DefaultTiers(), *historyCheckpointPath)
+		if err := historyStore.LoadCheckpoint(); err != nil {
+			log.Printf("history: failed to load checkpoint, starting with an empty store: %v", err)
+		}
+
+		historyCtx, cancelHistory := context.WithCancel(context.Background())
+		defer cancelHistory()
+		sampler := NewHistorySampler(historyStore, *historySampleInterval, snapshot)
+		go sampler.Run(historyCtx)
+		go runCheckpointLoop(historyCtx, historyStore, *historyCheckpointInterval)
+
+		http.HandleFunc("/metrics/history", historyHandler(historyStore))
+
+		// HTTP-Handler für das /download/excel-Endpunkt einrichten. Wird erst hier registriert (statt
+		// direkt neben /metrics), weil die Excel-Arbeitsmappe seit excel_workbook.go ein "History"-Blatt
+		// aus historyStore befüllt.
+		http.HandleFunc("/download/excel", downloadExcelHandler(historyStore))
+
+		// HTTP-Handler für die Namespace-/Workload-Sicht (siehe namespace_metrics.go): eine Ebene
+		// oberhalb von NodeMetrics, die zeigt, welcher Namespace/Workload wie viel Kapazität anfordert.
+		http.HandleFunc("/metrics/namespaces", namespacesHandler(clientset, *workloadNamespaces))
+		http.HandleFunc("/metrics/workloads", workloadsHandler(clientset, *workloadNamespaces))
+
+		// HTTP-Handler für das Prometheus-kompatible /prometheus-Endpunkt einrichten.
+		// Der Exporter cached die berechneten Metriken, damit häufige Scrapes nicht bei
+		// jedem Request erneut gegen die Kubernetes- und Metrics-API laufen.
+		exporter := NewMetricsExporter(clientset, metricsClient, *nodeType, *scrapeInterval)
+		http.Handle("/prometheus", exporter)
 
 		// Starten des HTTP-Servers
 		log.Println("Server startet auf :8080")
 		log.Fatal(http.ListenAndServe(":8080", nil))
 	} else {
 		// Ausführung im CLI-Modus
-		clientset, metricsClient := getClients(kubeconfig)
-		nodes := getNodes(clientset)
+		clientset, metricsClient, err := getClients(kubeconfig)
+		if err != nil {
+			log.Fatalf("Error creating Kubernetes clients: %v", err)
+		}
+		nodes, err := getNodes(clientset)
+		if err != nil {
+			log.Fatalf("Error listing nodes: %v", err)
+		}
 
 		fmt.Fprintln(os.Stderr, "CLI-Modus -- Knotentyp: ", *nodeType, "sammelt Metriken für Knoten")
-		clusterMetrics = calculateClusterMetrics(clientset, metricsClient, nodes, *nodeType)
+		clusterMetrics, err = calculateClusterMetrics(clientset, metricsClient, nodes, *nodeType)
+		if err != nil {
+			log.Fatalf("Error calculating cluster metrics: %v", err)
+		}
 		sortNodeMetricsByName(clusterMetrics.Nodes)
-		printASCIITable(clusterMetrics)
+		printASCIITable(clusterMetrics, includeOptions{})
 	}
 }
 
@@ -135,19 +377,43 @@ func main() {
 // Sie gibt die Werte der Flags als Zeiger zurück.
 //
 // Rückgabewerte:
-// - *string: Ein Zeiger auf den Wert des "node-type"-Flags, der den Knotentyp angibt (z.B. "worker" oder "infra").
-// - *bool: Ein Zeiger auf den Wert des "server"-Flags, der angibt, ob der Webserver gestartet werden soll.
-// - *string: Ein Zeiger auf den Wert des "service-account"-Flags, der den Namen des Service-Accounts angibt.
+//   - *string: Ein Zeiger auf den Wert des "node-type"-Flags, der den Knotentyp angibt (z.B. "worker" oder "infra").
+//   - *bool: Ein Zeiger auf den Wert des "server"-Flags, der angibt, ob der Webserver gestartet werden soll.
+//   - *string: Ein Zeiger auf den Wert des "service-account"-Flags, der den Namen des Service-Accounts angibt.
+//   - *string: Ein Zeiger auf den Wert des "kubeconfig"-Flags.
+//   - *time.Duration: Ein Zeiger auf den Wert des "scrape-interval"-Flags, der das Mindestintervall
+//     zwischen zwei Neuberechnungen der Metriken für den /prometheus-Endpunkt angibt.
+//   - *bool: Ein Zeiger auf den Wert des "use-watch-cache"-Flags.
+//   - *time.Duration: Ein Zeiger auf den Wert des "resync-period"-Flags für die Informer.
+//   - *time.Duration: Ein Zeiger auf den Wert des "metrics-poll-interval"-Flags für die metrics.k8s.io-Pollschleife.
+//   - *string: Ein Zeiger auf den Wert des "token-audience"-Flags, der die Audience für das
+//     TokenRequest-basierte Service-Account-Token im Pod-Modus angibt.
+//   - *time.Duration: Ein Zeiger auf den Wert des "history-sample-interval"-Flags für den
+//     /metrics/history-Sampler.
+//   - *string: Ein Zeiger auf den Wert des "history-checkpoint-path"-Flags; leer deaktiviert
+//     Checkpointing des /metrics/history-Stores.
+//   - *time.Duration: Ein Zeiger auf den Wert des "history-checkpoint-interval"-Flags.
+//   - *string: Ein Zeiger auf den Wert des "namespaces"-Flags (kommagetrennte Namespace-Liste für
+//     /metrics/namespaces und /metrics/workloads; leer bedeutet alle Namespaces).
 //
 // Beispiel:
 //
-//	nodeType, serverMode, sa := getFlags()
-func getFlags() (*string, *bool, *string, *string) {
+//	nodeType, serverMode, sa, kubeconfig, scrapeInterval, useWatchCache, resyncPeriod, metricsPollInterval, tokenAudience, historySampleInterval, historyCheckpointPath, historyCheckpointInterval, workloadNamespaces := getFlags()
+func getFlags() (*string, *bool, *string, *string, *time.Duration, *bool, *time.Duration, *time.Duration, *string, *time.Duration, *string, *time.Duration, *string) {
 	// Definiere Befehlszeilen-Flags
 	nodeType := flag.String("node-type", "worker", "Specify the node type (worker or infra)")
 	serverMode := flag.Bool("server", false, "Start the web server")
 	serviceaccountname := flag.String("sa", "scp", "Specify the service account name")
 	kubeconfig := flag.String("kubeconfig", "", "Path to the kubeconfig file")
+	scrapeInterval := flag.Duration("scrape-interval", 30*time.Second, "Minimum interval between metrics recomputations for the /prometheus endpoint")
+	useWatchCache := flag.Bool("use-watch-cache", false, "Use a shared informer/watch-based cache for nodes and pods instead of listing them on every request")
+	resyncPeriod := flag.Duration("resync-period", 10*time.Minute, "Resync period for the node/pod informers when --use-watch-cache is set")
+	metricsPollInterval := flag.Duration("metrics-poll-interval", 30*time.Second, "Poll interval for metrics.k8s.io when --use-watch-cache is set (metrics can't be watched)")
+	tokenAudience := flag.String("token-audience", "", "Audience for the TokenRequest-based service account token requested in Pod mode (empty uses the cluster's default API server audience)")
+	historySampleInterval := flag.Duration("history-sample-interval", 30*time.Second, "Sampling interval for the /metrics/history time-series store")
+	historyCheckpointPath := flag.String("history-checkpoint-path", "", "Path to a checkpoint file for the /metrics/history store; empty disables checkpointing (history is lost on restart)")
+	historyCheckpointInterval := flag.Duration("history-checkpoint-interval", 5*time.Minute, "Interval between /metrics/history checkpoint writes when --history-checkpoint-path is set")
+	workloadNamespaces := flag.String("namespaces", "", "Comma-separated list of namespaces to include in /metrics/namespaces and /metrics/workloads; empty searches all namespaces")
 
 	// Parse die Befehlszeilen-Flags
 	flag.Parse()
@@ -158,78 +424,228 @@ func getFlags() (*string, *bool, *string, *string) {
 	}
 
 	// Gib die Werte der Flags zurück
-	return nodeType, serverMode, serviceaccountname, kubeconfig
+	return nodeType, serverMode, serviceaccountname, kubeconfig, scrapeInterval, useWatchCache, resyncPeriod, metricsPollInterval, tokenAudience, historySampleInterval, historyCheckpointPath, historyCheckpointInterval, workloadNamespaces
 }
 
 // getPodClients erstellt Kubernetes- und Metrik-Clients unter Verwendung der In-Cluster-Konfiguration.
 //
 // Diese Funktion wird verwendet, um Kubernetes- und Metrik-Clients zu erstellen, die innerhalb eines Kubernetes-Clusters ausgeführt werden.
-// Sie verwendet die In-Cluster-Konfiguration, um die notwendigen Verbindungsinformationen zu erhalten.
+// Sie verwendet die In-Cluster-Konfiguration (projiziertes Token, das client-go beim Lesen der Datei
+// automatisch erneuert) als Bootstrap-Identität, um für den konfigurierten Service-Account (--sa) über
+// die TokenRequest-API ein zeitlich begrenztes Token anzufordern. Die alte Secret-basierte Token-Abfrage
+// (getTokenFromSecret) wurde entfernt, weil Kubernetes 1.24+ für Service-Accounts keine Token-Secrets
+// mehr automatisch anlegt.
 //
 // Rückgabewerte:
-// - *kubernetes.Clientset: Ein Clientset, das verwendet wird, um mit der Kubernetes-API zu kommunizieren.
-// - *metricsv.Clientset: Ein Clientset, das verwendet wird, um Metriken von Kubernetes-Ressourcen abzurufen.
+//   - *kubernetes.Clientset: Ein Clientset, das verwendet wird, um mit der Kubernetes-API zu kommunizieren.
+//   - *metricsv.Clientset: Ein Clientset, das verwendet wird, um Metriken von Kubernetes-Ressourcen abzurufen.
+//   - error: Ein Fehlerobjekt, falls ein Schritt beim Aufbau der Clients fehlschlägt oder dem
+//     Service-Account eine der in requiredRBACChecks gelisteten Berechtigungen fehlt.
 //
 // Fehler:
-// Diese Funktion beendet das Programm mit einem log.Fatalf-Aufruf, wenn ein Fehler auftritt, z.B. beim Erstellen der In-Cluster-Konfiguration,
-// beim Abrufen des aktuellen Namespaces oder beim Abrufen des Tokens aus dem Secret.
+// Diese Funktion gibt einen Fehler zurück statt das Programm zu beenden, z.B. beim Erstellen der
+// In-Cluster-Konfiguration, beim Abrufen des aktuellen Namespaces, beim Anfordern des TokenRequest-Tokens
+// oder wenn checkRBACPermissions eine fehlende Berechtigung meldet. So kann der Aufrufer entscheiden, wie
+// auf den Fehler reagiert wird (z.B. HTTP 5xx statt Prozessabbruch).
 //
 // Beispiel:
 //
-//	clientset, metricsClient := getPodClients()
+//	clientset, metricsClient, err := getPodClients()
 //
 // Ablauf:
-// 1. Erstellt die In-Cluster-Konfiguration.
-// 2. Erstellt ein neues Kubernetes-Clientset.
-// 3. Ruft den aktuellen Namespace ab.
-// 4. Ruft das Token aus dem Secret ab, das mit dem Service-Account verknüpft ist.
-// 5. Setzt das BearerToken in der Konfiguration auf das abgerufene Token.
-// 6. Erstellt ein neues Metrik-Clientset unter Verwendung der aktualisierten Konfiguration.
-// 7. Gibt das Kubernetes-Clientset und das Metrik-Clientset zurück.
-func getPodClients() (*kubernetes.Clientset, *metricsv.Clientset) {
-	// Create in-cluster config
+//  1. Erstellt die In-Cluster-Konfiguration und daraus ein Bootstrap-Clientset.
+//  2. Ruft den aktuellen Namespace ab.
+//  3. Baut eine Config, deren Transport ein audienceTokenRoundTripper umhüllt, der das
+//     TokenRequest-Token für den konfigurierten Service-Account vor Ablauf automatisch erneuert.
+//  4. Erstellt Kubernetes- und Metrik-Clientset mit dieser Config.
+//  5. Prüft per SelfSubjectAccessReview, ob der Service-Account die Mindestberechtigungen hat, und
+//     bricht mit einer klaren Fehlermeldung ab, falls nicht.
+//  6. Gibt das Kubernetes-Clientset und das Metrik-Clientset zurück.
+func getPodClients() (*kubernetes.Clientset, *metricsv.Clientset, error) {
+	// Create in-cluster config. rest.InClusterConfig() already points at the projected service
+	// account token file, which client-go reloads automatically as Kubernetes rotates it.
 	config, err := rest.InClusterConfig()
 	if err != nil {
-		log.Fatalf("Error creating in-cluster config: %v", err)
-	} else {
-		log.Println("InClusterConfig", config)
+		return nil, nil, fmt.Errorf("error creating in-cluster config: %v", err)
 	}
 
-	// Create a new Kubernetes clientset
-	clientset, err := kubernetes.NewForConfig(config)
+	// Bootstrap clientset, authenticated as the Pod's own service account, used only to mint
+	// TokenRequest tokens for *serviceaccountname.
+	bootstrapClientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		log.Fatalf("Error creating Kubernetes client: %v", err)
-	} else {
-		log.Println("KubernetesClient", clientset)
+		return nil, nil, fmt.Errorf("error creating Kubernetes client: %v", err)
 	}
 
 	// Get the current namespace
 	namespace, err := getCurrentNamespace()
 	if err != nil {
-		log.Fatalf("Error getting current namespace: %v", err)
-	} else {
-		log.Println("Namespace", namespace)
+		return nil, nil, fmt.Errorf("error getting current namespace: %v", err)
+	}
+
+	// Build a config that authenticates as *serviceaccountname via a periodically refreshed
+	// TokenRequest token instead of a static BearerToken.
+	saConfig := rest.CopyConfig(config)
+	saConfig.BearerToken = ""
+	saConfig.BearerTokenFile = ""
+	saConfig.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+		return &audienceTokenRoundTripper{
+			base:               rt,
+			clientset:          bootstrapClientset,
+			namespace:          namespace,
+			serviceAccountName: *serviceaccountname,
+			audience:           *tokenAudience,
+		}
 	}
 
-	// Get the token from the secret associated with the service account
-	token, err := getTokenFromSecret(clientset, namespace, *serviceaccountname)
+	// Create a new Kubernetes clientset using the refreshing config
+	clientset, err := kubernetes.NewForConfig(saConfig)
 	if err != nil {
-		log.Fatalf("Error getting token from secret: %v", err)
-	} else {
-		log.Println("Token", token)
+		return nil, nil, fmt.Errorf("error creating Kubernetes client: %v", err)
 	}
 
-	// Set the BearerToken in the config to the token retrieved from the secret
-	config.BearerToken = token
+	// Fail fast if the service account is missing a required permission instead of surfacing a
+	// confusing 403 on the first real Nodes().List/Pods().List call.
+	if err := checkRBACPermissions(clientset); err != nil {
+		return nil, nil, err
+	}
 
-	// Create a new metrics client using the updated config
-	metricsClient, err := metricsv.NewForConfig(config)
+	// Create a new metrics client using the same refreshing config
+	metricsClient, err := metricsv.NewForConfig(saConfig)
 	if err != nil {
-		log.Fatalf("Error creating metrics client: %v", err)
+		return nil, nil, fmt.Errorf("error creating metrics client: %v", err)
 	}
 
 	// Return the Kubernetes clientset and metrics client
-	return clientset, metricsClient
+	return clientset, metricsClient, nil
+}
+
+// audienceTokenRoundTripper setzt auf jede ausgehende Anfrage ein per TokenRequest-API angefordertes
+// Bearer-Token und erneuert es kurz vor Ablauf automatisch, statt ein einmalig geladenes Token zu
+// verwenden. Das ersetzt die frühere Secret-basierte Token-Abfrage, die auf Kubernetes 1.24+ nicht mehr
+// funktioniert, weil Service-Account-Token-Secrets dort nicht mehr automatisch angelegt werden.
+type audienceTokenRoundTripper struct {
+	base               http.RoundTripper
+	clientset          *kubernetes.Clientset
+	namespace          string
+	serviceAccountName string
+	audience           string
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// tokenRefreshMargin legt fest, wie lange vor Ablauf ein TokenRequest-Token erneuert wird, damit eine
+// in Flight befindliche Anfrage nicht mit einem gerade abgelaufenen Token scheitert.
+const tokenRefreshMargin = 1 * time.Minute
+
+// currentToken gibt das aktuell gültige Token zurück und fordert bei Bedarf über die TokenRequest-API
+// ein neues an.
+func (rt *audienceTokenRoundTripper) currentToken() (string, error) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if rt.token != "" && time.Until(rt.expiresAt) > tokenRefreshMargin {
+		return rt.token, nil
+	}
+
+	token, expiresAt, err := createAudienceScopedToken(rt.clientset, rt.namespace, rt.serviceAccountName, rt.audience)
+	if err != nil {
+		return "", err
+	}
+	rt.token, rt.expiresAt = token, expiresAt
+	return rt.token, nil
+}
+
+// RoundTrip implementiert http.RoundTripper und setzt den Authorization-Header auf das aktuell
+// gültige TokenRequest-Token, bevor die Anfrage an den zugrunde liegenden Transport weitergereicht wird.
+func (rt *audienceTokenRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := rt.currentToken()
+	if err != nil {
+		return nil, fmt.Errorf("error refreshing service account token: %v", err)
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return rt.base.RoundTrip(req)
+}
+
+// createAudienceScopedToken fordert über die TokenRequest-API (ServiceAccounts(namespace).CreateToken)
+// ein zeitlich begrenztes Token für serviceAccountName an. Ist audience leer, verwendet der API-Server
+// seine Standard-Audience. Diese Funktion ersetzt die frühere getTokenFromSecret, die auf das
+// automatische Anlegen eines Token-Secrets durch Kubernetes angewiesen war.
+func createAudienceScopedToken(clientset *kubernetes.Clientset, namespace, serviceAccountName, audience string) (string, time.Time, error) {
+	tokenRequest := &authenticationv1.TokenRequest{}
+	if audience != "" {
+		tokenRequest.Spec.Audiences = []string{audience}
+	}
+
+	result, err := clientset.CoreV1().ServiceAccounts(namespace).CreateToken(context.TODO(), serviceAccountName, tokenRequest, metav1.CreateOptions{})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error creating token request for service account %q: %v", serviceAccountName, err)
+	}
+	return result.Status.Token, result.Status.ExpirationTimestamp.Time, nil
+}
+
+// requiredRBACChecks sind die Mindestberechtigungen, die der Service-Account braucht, um Knoten und
+// Pods cluster-weit, deren metrics.k8s.io-Nutzungsdaten sowie die Workload-Ressourcen hinter
+// /metrics/namespaces und /metrics/workloads zu lesen. checkRBACPermissions prüft jeden Eintrag per
+// SelfSubjectAccessReview. Ein passender ClusterRole/ClusterRoleBinding für den Service-Account aus
+// --sa sieht entsprechend so aus:
+//
+//	apiVersion: rbac.authorization.k8s.io/v1
+//	kind: ClusterRole
+//	metadata:
+//	  name: cluster-resources-reader
+//	rules:
+//	  - apiGroups: [""]
+//	    resources: ["nodes", "pods"]
+//	    verbs: ["list"]
+//	  - apiGroups: ["metrics.k8s.io"]
+//	    resources: ["nodes"]
+//	    verbs: ["get"]
+//	  - apiGroups: ["metrics.k8s.io"]
+//	    resources: ["pods"]
+//	    verbs: ["list"]
+//	  - apiGroups: ["apps"]
+//	    resources: ["deployments", "statefulsets", "daemonsets"]
+//	    verbs: ["list"]
+var requiredRBACChecks = []authorizationv1.ResourceAttributes{
+	{Verb: "list", Group: "", Resource: "nodes"},
+	{Verb: "list", Group: "", Resource: "pods"},
+	{Verb: "get", Group: "metrics.k8s.io", Resource: "nodes"},
+	{Verb: "list", Group: "metrics.k8s.io", Resource: "pods"},
+	{Verb: "list", Group: "apps", Resource: "deployments"},
+	{Verb: "list", Group: "apps", Resource: "statefulsets"},
+	{Verb: "list", Group: "apps", Resource: "daemonsets"},
+}
+
+// checkRBACPermissions führt für jeden Eintrag in requiredRBACChecks eine SelfSubjectAccessReview aus
+// und gibt einen Fehler zurück, der alle fehlenden Verben auflistet, sobald dem Service-Account
+// mindestens eine der Mindestberechtigungen fehlt.
+func checkRBACPermissions(clientset *kubernetes.Clientset) error {
+	var missing []string
+	for _, resourceAttributes := range requiredRBACChecks {
+		resourceAttributes := resourceAttributes
+		review, err := clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(context.TODO(), &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &resourceAttributes,
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("error checking RBAC permission %s %s.%s: %v", resourceAttributes.Verb, resourceAttributes.Resource, resourceAttributes.Group, err)
+		}
+		if !review.Status.Allowed {
+			group := resourceAttributes.Group
+			if group == "" {
+				group = "core"
+			}
+			missing = append(missing, fmt.Sprintf("%s %s (%s)", resourceAttributes.Verb, resourceAttributes.Resource, group))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("service account %q is missing required RBAC permissions: %s", *serviceaccountname, strings.Join(missing, ", "))
+	}
+	return nil
 }
 
 // getClients erstellt Kubernetes- und Metrik-Clients unter Verwendung der bereitgestellten kubeconfig-Datei.
@@ -245,40 +661,40 @@ func getPodClients() (*kubernetes.Clientset, *metricsv.Clientset) {
 // - *metricsv.Clientset: Ein Clientset, das verwendet wird, um Metriken von Kubernetes-Ressourcen abzurufen.
 //
 // Fehler:
-// Diese Funktion beendet das Programm mit einem log.Fatalf-Aufruf, wenn ein Fehler auftritt, z.B. beim Erstellen der Konfiguration
-// aus der kubeconfig-Datei oder beim Erstellen der Kubernetes- oder Metrik-Clients.
+// Diese Funktion gibt einen Fehler zurück statt das Programm zu beenden, wenn ein Schritt fehlschlägt,
+// z.B. beim Erstellen der Konfiguration aus der kubeconfig-Datei oder beim Erstellen der Clients.
 //
 // Beispiel:
 //
 //	kubeconfig := "/path/to/kubeconfig"
-//	clientset, metricsClient := getClients(&kubeconfig)
+//	clientset, metricsClient, err := getClients(&kubeconfig)
 //
 // Ablauf:
 // 1. Erstellt die Konfiguration aus der kubeconfig-Datei.
 // 2. Erstellt ein neues Kubernetes-Clientset.
 // 3. Erstellt ein neues Metrik-Clientset.
 // 4. Gibt das Kubernetes-Clientset und das Metrik-Clientset zurück.
-func getClients(kubeconfig *string) (*kubernetes.Clientset, *metricsv.Clientset) {
+func getClients(kubeconfig *string) (*kubernetes.Clientset, *metricsv.Clientset, error) {
 	// Build the config from the kubeconfig file
 	config, err := clientcmd.BuildConfigFromFlags("", *kubeconfig)
 	if err != nil {
-		log.Fatalf("Error building kubeconfig: %v", err)
+		return nil, nil, fmt.Errorf("error building kubeconfig: %v", err)
 	}
 
 	// Create a new Kubernetes clientset
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		log.Fatalf("Error creating Kubernetes client: %v", err)
+		return nil, nil, fmt.Errorf("error creating Kubernetes client: %v", err)
 	}
 
 	// Create a new metrics client
 	metricsClient, err := metricsv.NewForConfig(config)
 	if err != nil {
-		log.Fatalf("Error creating metrics client: %v", err)
+		return nil, nil, fmt.Errorf("error creating metrics client: %v", err)
 	}
 
 	// Return the Kubernetes clientset and metrics client
-	return clientset, metricsClient
+	return clientset, metricsClient, nil
 }
 
 // getCurrentNamespace liest den aktuellen Namespace aus der Service-Account-Token-Datei.
@@ -313,78 +729,6 @@ func getCurrentNamespace() (string, error) {
 	return string(namespaceBytes), nil
 }
 
-// getTokenFromSecret ruft das Token aus dem Secret ab, das mit dem angegebenen Service-Account verknüpft ist.
-//
-// Diese Funktion wird verwendet, um das Token aus dem Secret eines bestimmten Service-Accounts in einem bestimmten Namespace abzurufen.
-// Das Token wird benötigt, um authentifizierte Anfragen an die Kubernetes-API zu stellen.
-//
-// Parameter:
-// - clientset: Ein Kubernetes-Clientset, das verwendet wird, um mit der Kubernetes-API zu kommunizieren.
-// - namespace: Der Namespace, in dem sich der Service-Account befindet.
-// - serviceAccountName: Der Name des Service-Accounts, dessen Token abgerufen werden soll.
-//
-// Rückgabewerte:
-// - string: Das abgerufene Token als String.
-// - error: Ein Fehlerobjekt, falls ein Fehler beim Abrufen des Service-Accounts oder des Secrets auftritt oder das Token nicht im Secret gefunden wird.
-//
-// Fehler:
-// Diese Funktion gibt einen Fehler zurück, wenn:
-// - Der Service-Account nicht abgerufen werden kann.
-// - Der Service-Account keine Secrets hat.
-// - Das Secret nicht abgerufen werden kann.
-// - Das Token nicht im Secret gefunden wird.
-//
-// Beispiel:
-//
-//	token, err := getTokenFromSecret(clientset, "default", "my-service-account")
-//	if err != nil {
-//	    log.Fatalf("Error getting token from secret: %v", err)
-//	}
-//
-// Ablauf:
-// 1. Ruft den Service-Account im angegebenen Namespace ab.
-// 2. Überprüft, ob der Service-Account Secrets hat.
-// 3. Ruft das erste Secret des Service-Accounts ab.
-// 4. Ruft das Token aus dem Secret ab.
-// 5. Gibt das Token als String zurück.
-func getTokenFromSecret(clientset *kubernetes.Clientset, namespace, serviceAccountName string) (string, error) {
-	// Get the service account
-	sa, err := clientset.CoreV1().ServiceAccounts(namespace).Get(context.TODO(), serviceAccountName, metav1.GetOptions{})
-	if err != nil {
-		return "", fmt.Errorf("error getting service account: %v", err)
-	}
-
-	// Check if the service account has any secrets
-	if len(sa.Secrets) == 0 {
-		return "", fmt.Errorf("no secrets found for service account: %s", serviceAccountName)
-	}
-
-	// Get the secret associated with the service account
-	var secretName string
-	for _, secret := range sa.Secrets {
-		if strings.Contains(secret.Name, "token") {
-			secretName = secret.Name
-			break
-		}
-	}
-
-	if secretName == "" {
-		return "", fmt.Errorf("no secret with 'token' in the name found")
-	}
-	secret, err := clientset.CoreV1().Secrets(namespace).Get(context.TODO(), secretName, metav1.GetOptions{})
-	if err != nil {
-		return "", fmt.Errorf("error getting secret: %v", err)
-	}
-
-	// Retrieve the token from the secret
-	token, ok := secret.Data["token"]
-	if !ok {
-		return "", fmt.Errorf("token not found in secret: %s", secretName)
-	}
-
-	return string(token), nil
-}
-
 // getNodes ruft die Liste der Knoten im Cluster ab.
 //
 // Diese Funktion wird verwendet, um eine Liste aller Knoten im Kubernetes-Cluster abzurufen.
@@ -395,24 +739,27 @@ func getTokenFromSecret(clientset *kubernetes.Clientset, namespace, serviceAccou
 //
 // Rückgabewerte:
 // - *v1.NodeList: Eine Liste der Knoten im Cluster.
+// - error: Ein Fehlerobjekt, falls das Abrufen der Knotenliste fehlschlägt.
 //
 // Fehler:
-// Diese Funktion beendet das Programm mit einem log.Fatalf-Aufruf, wenn ein Fehler beim Abrufen der Knotenliste auftritt.
+// Diese Funktion gibt einen Fehler zurück statt das Programm zu beenden, wenn das Abrufen der
+// Knotenliste fehlschlägt, damit der Aufrufer (CLI oder HTTP-Handler) selbst entscheiden kann, wie
+// reagiert wird.
 //
 // Beispiel:
 //
-//	nodes := getNodes(clientset)
+//	nodes, err := getNodes(clientset)
 //
 // Ablauf:
 // 1. Listet die Knoten im Cluster unter Verwendung des Kubernetes-Clientsets.
 // 2. Gibt die Liste der Knoten zurück.
-func getNodes(clientset *kubernetes.Clientset) *v1.NodeList {
+func getNodes(clientset *kubernetes.Clientset) (*v1.NodeList, error) {
 	// List the nodes in the cluster
 	nodes, err := clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
-		log.Fatalf("Error listing nodes: %v", err)
+		return nil, fmt.Errorf("error listing nodes: %v", err)
 	}
-	return nodes
+	return nodes, nil
 }
 
 // calculateClusterMetrics berechnet die Metriken für den gesamten Cluster basierend auf den Metriken der einzelnen Knoten.
@@ -427,27 +774,46 @@ func getNodes(clientset *kubernetes.Clientset) *v1.NodeList {
 // - nodeType: Der Typ der Knoten, für die die Metriken berechnet werden sollen (z.B. "master", "worker").
 //
 // Rückgabewerte:
-// - ClusterMetrics: Eine Struktur, die die berechneten Metriken für den gesamten Cluster enthält.
+//   - ClusterMetrics: Eine Struktur, die die berechneten Metriken für den gesamten Cluster enthält.
+//   - error: Ein Fehlerobjekt, falls die Metrikberechnung für mindestens einen Knoten fehlschlägt
+//     (z.B. weil die Pod-Liste für diesen Knoten nicht abgerufen werden konnte). Ein fehlendes
+//     metrics-server-Sample allein führt NICHT zu einem Fehler, siehe calculateNodeMetrics.
 //
 // Beispiel:
 //
-//	clusterMetrics := calculateClusterMetrics(clientset, metricsClient, nodes, "worker")
+//	clusterMetrics, err := calculateClusterMetrics(clientset, metricsClient, nodes, "worker")
 //
 // Ablauf:
 // 1. Initialisiert Variablen für die Gesamtsummen der verschiedenen Metriken.
-// 2. Verwendet eine WaitGroup, um die parallele Verarbeitung der Knoten zu synchronisieren.
-// 3. Iteriert über alle Knoten im Cluster und startet eine Goroutine zur Berechnung der Metriken für jeden Knoten des angegebenen Typs.
-// 4. Wartet, bis alle Goroutines abgeschlossen sind, und sammelt die Metriken der einzelnen Knoten.
-// 5. Addiert die Metriken der einzelnen Knoten zu den Gesamtsummen.
-// 6. Erstellt und gibt die ClusterMetrics-Struktur zurück.
-func calculateClusterMetrics(clientset *kubernetes.Clientset, metricsClient *metricsv.Clientset, nodes *v1.NodeList, nodeType string) ClusterMetrics {
+// 2. Holt einmalig die Pod-Metriken des gesamten Clusters, damit nicht jede Node-Goroutine sie erneut abruft.
+// 3. Verwendet eine WaitGroup, um die parallele Verarbeitung der Knoten zu synchronisieren.
+// 4. Iteriert über alle Knoten im Cluster und startet eine Goroutine zur Berechnung der Metriken für jeden Knoten des angegebenen Typs.
+// 5. Wartet, bis alle Goroutines abgeschlossen sind, und sammelt die Metriken der einzelnen Knoten.
+// 6. Addiert die Metriken der einzelnen Knoten zu den Gesamtsummen.
+// 7. Erstellt und gibt die ClusterMetrics-Struktur zurück.
+func calculateClusterMetrics(clientset *kubernetes.Clientset, metricsClient *metricsv.Clientset, nodes *v1.NodeList, nodeType string) (ClusterMetrics, error) {
 	// Initialisiere Variablen für die Gesamtsummen der verschiedenen Metriken
 	var totalPhysicalCPU, totalPhysicalMemory, totalRequestedCPU, totalRequestedMem, totalLimitsCPU, totalLimitsMem, totalUsedCPU, totalUsedMem resource.Quantity
+	var totalEphemeralCapacity, totalEphemeralRequests, totalEphemeralLimits resource.Quantity
 	var nodeMetricsList []NodeMetrics
 
+	// Hole die Pod-Metriken des gesamten Clusters genau einmal, statt sie in jeder Node-Goroutine
+	// erneut per API-Call abzurufen (bei N Knoten sonst N identische PodMetricses-Listen pro Request).
+	// Ist der metrics-server nicht installiert/erreichbar, werden alle Used*-Felder der Pods/Container
+	// auf "n/a" gesetzt statt die Berechnung abzubrechen (siehe calculateNodeMetrics).
+	podMetricsList, err := metricsClient.MetricsV1beta1().PodMetricses("").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		log.Printf("Warning: pod metrics unavailable (%v), reporting used values as n/a", err)
+		podMetricsList = nil
+	}
+
 	// Verwende einen WaitGroup, um die parallele Verarbeitung der Knoten zu synchronisieren
 	var wg sync.WaitGroup
-	nodeMetricsChan := make(chan NodeMetrics, len(nodes.Items))
+	type nodeResult struct {
+		metrics NodeMetrics
+		err     error
+	}
+	resultChan := make(chan nodeResult, len(nodes.Items))
 
 	// Iteriere über alle Knoten im Cluster
 	for _, node := range nodes.Items {
@@ -457,18 +823,26 @@ func calculateClusterMetrics(clientset *kubernetes.Clientset, metricsClient *met
 			// Starte eine Goroutine zur Berechnung der Metriken für den Knoten
 			go func(node v1.Node) {
 				defer wg.Done()
-				nodeMetrics := calculateNodeMetrics(clientset, metricsClient, node, nodeType)
-				nodeMetricsChan <- nodeMetrics
+				nodeMetrics, err := calculateNodeMetrics(clientset, metricsClient, node, nodeType, podMetricsList)
+				resultChan <- nodeResult{metrics: nodeMetrics, err: err}
 			}(node)
 		}
 	}
 
 	// Warte, bis alle Goroutines abgeschlossen sind
 	wg.Wait()
-	close(nodeMetricsChan)
-
-	// Sammle die Metriken der einzelnen Knoten und addiere sie zu den Gesamtsummen
-	for nodeMetrics := range nodeMetricsChan {
+	close(resultChan)
+
+	// Sammle die Metriken der einzelnen Knoten und addiere sie zu den Gesamtsummen. Ein Fehler bei
+	// einem einzelnen Knoten wird gesammelt und am Ende zurückgegeben, verhindert aber nicht die
+	// Verarbeitung der übrigen Knoten.
+	var errs []string
+	for result := range resultChan {
+		if result.err != nil {
+			errs = append(errs, result.err.Error())
+			continue
+		}
+		nodeMetrics := result.metrics
 		nodeMetricsList = append(nodeMetricsList, nodeMetrics)
 		totalPhysicalCPU.Add(resource.MustParse(nodeMetrics.PhysicalCPU))
 		totalPhysicalMemory.Add(resource.MustParse(nodeMetrics.PhysicalMemory))
@@ -476,12 +850,18 @@ func calculateClusterMetrics(clientset *kubernetes.Clientset, metricsClient *met
 		totalRequestedMem.Add(resource.MustParse(nodeMetrics.RequestedMemory))
 		totalLimitsCPU.Add(resource.MustParse(nodeMetrics.LimitsCPU))
 		totalLimitsMem.Add(resource.MustParse(nodeMetrics.LimitsMemory))
-		totalUsedCPU.Add(resource.MustParse(nodeMetrics.UsedCPU))
-		totalUsedMem.Add(resource.MustParse(nodeMetrics.UsedMemory))
+		if used, err := resource.ParseQuantity(nodeMetrics.UsedCPU); err == nil {
+			totalUsedCPU.Add(used)
+		}
+		if used, err := resource.ParseQuantity(nodeMetrics.UsedMemory); err == nil {
+			totalUsedMem.Add(used)
+		}
+		totalEphemeralCapacity.Add(resource.MustParse(nodeMetrics.EphemeralStorageCapacity))
+		totalEphemeralRequests.Add(resource.MustParse(nodeMetrics.EphemeralStorageRequests))
+		totalEphemeralLimits.Add(resource.MustParse(nodeMetrics.EphemeralStorageLimits))
 	}
 
-	// Erstelle und gib die ClusterMetrics-Struktur zurück
-	return ClusterMetrics{
+	clusterMetrics := ClusterMetrics{
 		Nodes:                nodeMetricsList,
 		TotalPhysicalCPU:     convertCpuStr(totalPhysicalCPU),
 		TotalPhysicalMemory:  convertMemStr(totalPhysicalMemory),
@@ -491,7 +871,29 @@ func calculateClusterMetrics(clientset *kubernetes.Clientset, metricsClient *met
 		TotalLimitsMemory:    convertMemStr(totalLimitsMem),
 		TotalUsedCPU:         convertCpuStr(totalUsedCPU),
 		TotalUsedMemory:      convertMemStr(totalUsedMem),
+
+		TotalPhysicalCPUQuantity:     totalPhysicalCPU,
+		TotalPhysicalMemoryQuantity:  totalPhysicalMemory,
+		TotalRequestedCPUQuantity:    totalRequestedCPU,
+		TotalRequestedMemoryQuantity: totalRequestedMem,
+		TotalLimitsCPUQuantity:       totalLimitsCPU,
+		TotalLimitsMemoryQuantity:    totalLimitsMem,
+		TotalUsedCPUQuantity:         totalUsedCPU,
+		TotalUsedMemoryQuantity:      totalUsedMem,
+
+		TotalEphemeralStorageCapacity: convertMemStr(totalEphemeralCapacity),
+		TotalEphemeralStorageRequests: convertMemStr(totalEphemeralRequests),
+		TotalEphemeralStorageLimits:   convertMemStr(totalEphemeralLimits),
+
+		TotalEphemeralStorageCapacityQuantity: totalEphemeralCapacity,
+		TotalEphemeralStorageRequestsQuantity: totalEphemeralRequests,
+		TotalEphemeralStorageLimitsQuantity:   totalEphemeralLimits,
+	}
+
+	if len(errs) > 0 {
+		return clusterMetrics, fmt.Errorf("error calculating metrics for %d node(s): %s", len(errs), strings.Join(errs, "; "))
 	}
+	return clusterMetrics, nil
 }
 
 // calculateNodeMetrics berechnet die Metriken für einen einzelnen Knoten.
@@ -500,32 +902,37 @@ func calculateClusterMetrics(clientset *kubernetes.Clientset, metricsClient *met
 // Sie sammelt die angeforderten und begrenzten Ressourcen aller Pods auf dem Knoten sowie die aktuellen Nutzungsmetriken des Knotens.
 //
 // Parameter:
-// - clientset: Ein Kubernetes-Clientset, das verwendet wird, um mit der Kubernetes-API zu kommunizieren.
-// - metricsClient: Ein Clientset, das verwendet wird, um Metriken von Kubernetes-Ressourcen abzurufen.
-// - node: Der Knoten, für den die Metriken berechnet werden sollen.
-// - nodeType: Der Typ des Knotens (z.B. "master", "worker").
+//   - clientset: Ein Kubernetes-Clientset, das verwendet wird, um mit der Kubernetes-API zu kommunizieren.
+//   - metricsClient: Ein Clientset, das verwendet wird, um Metriken von Kubernetes-Ressourcen abzurufen.
+//   - node: Der Knoten, für den die Metriken berechnet werden sollen.
+//   - nodeType: Der Typ des Knotens (z.B. "master", "worker").
+//   - podMetricsList: Die Pod-Metriken des gesamten Clusters, einmalig von calculateClusterMetrics
+//     abgerufen (siehe dort) statt hier erneut per API-Call geholt zu werden; nil, falls der metrics-server
+//     nicht erreichbar war.
 //
 // Rückgabewerte:
-// - NodeMetrics: Eine Struktur, die die berechneten Metriken für den Knoten enthält.
-//
-// Fehler:
-// Diese Funktion beendet das Programm mit einem log.Fatalf-Aufruf, wenn ein Fehler beim Abrufen der Pods oder der Metriken auftritt.
+//   - NodeMetrics: Eine Struktur, die die berechneten Metriken für den Knoten enthält.
+//   - error: Ein Fehlerobjekt, falls das Abrufen der Pod-Liste fehlschlägt. Ein fehlendes metrics-server-
+//     Sample führt dagegen NICHT zu einem Fehler: Capacity/Requests/Limits werden trotzdem berechnet und
+//     alle Used*-Felder sowie die davon abhängigen Utilization-Felder werden auf "n/a" gesetzt.
 //
 // Beispiel:
 //
-//	nodeMetrics := calculateNodeMetrics(clientset, metricsClient, node, "worker")
+//	nodeMetrics, err := calculateNodeMetrics(clientset, metricsClient, node, "worker", podMetricsList)
 //
 // Ablauf:
-// 1. Initialisiert Variablen für die verschiedenen Metriken.
-// 2. Listet alle Pods auf dem angegebenen Knoten auf.
-// 3. Iteriert über alle Pods und deren Container, um die angeforderten und begrenzten Ressourcen zu summieren.
-// 4. Holt die aktuellen Nutzungsmetriken für den Knoten.
-// 5. Addiert die aktuellen Nutzungsmetriken zu den Gesamtsummen.
-// 6. Holt die physische Kapazität des Knotens.
-// 7. Erstellt und gibt die NodeMetrics-Struktur zurück.
-func calculateNodeMetrics(clientset *kubernetes.Clientset, metricsClient *metricsv.Clientset, node v1.Node, nodeType string) NodeMetrics {
+//  1. Initialisiert Variablen für die verschiedenen Metriken.
+//  2. Listet alle Pods auf dem angegebenen Knoten auf.
+//  3. Iteriert über alle Pods und deren Container, um die angeforderten und begrenzten Ressourcen zu summieren.
+//  4. Holt die aktuellen Nutzungsmetriken für den Knoten; ist der metrics-server nicht erreichbar,
+//     werden die Used*-Felder auf "n/a" gesetzt statt die Berechnung abzubrechen.
+//  5. Addiert die aktuellen Nutzungsmetriken zu den Gesamtsummen.
+//  6. Holt die physische Kapazität des Knotens.
+//  7. Erstellt und gibt die NodeMetrics-Struktur zurück.
+func calculateNodeMetrics(clientset *kubernetes.Clientset, metricsClient *metricsv.Clientset, node v1.Node, nodeType string, podMetricsList *metricsapi.PodMetricsList) (NodeMetrics, error) {
 	// Initialisiere Variablen für die verschiedenen Metriken
 	var nodeRequestedCPU, nodeRequestedMem, nodeLimitsCPU, nodeLimitsMem, nodeUsedCPU, nodeUsedMem resource.Quantity
+	var nodeRequestedEphemeral, nodeLimitsEphemeral resource.Quantity
 
 	// Erfassen der Labels des Nodes
 	labels := node.Labels
@@ -545,11 +952,33 @@ func calculateNodeMetrics(clientset *kubernetes.Clientset, metricsClient *metric
 		FieldSelector: fmt.Sprintf("spec.nodeName=%s", node.Name),
 	})
 	if err != nil {
-		log.Fatalf("Error listing pods on node %s: %v", node.Name, err)
+		return NodeMetrics{}, fmt.Errorf("error listing pods on node %s: %v", node.Name, err)
 	}
 
+	// Indiziere die (von calculateClusterMetrics einmalig für den gesamten Cluster abgerufenen)
+	// Pod-Metriken nach Namespace/Name, damit sie unten je Pod und Container nachgeschlagen werden
+	// können. Ist podMetricsList nil (metrics-server nicht installiert/erreichbar), bleibt die
+	// Pod-Nutzung einfach leer statt die Berechnung abzubrechen - Requests/Limits lassen sich auch
+	// ohne metrics-server berechnen.
+	podMetricsAvailable := podMetricsList != nil
+	podUsageByKey := make(map[string]map[string]v1.ResourceList)
+	if podMetricsList != nil {
+		for _, pm := range podMetricsList.Items {
+			containerUsage := make(map[string]v1.ResourceList, len(pm.Containers))
+			for _, c := range pm.Containers {
+				containerUsage[c.Name] = c.Usage
+			}
+			podUsageByKey[pm.Namespace+"/"+pm.Name] = containerUsage
+		}
+	}
+
+	var podMetricsOnNode []PodMetrics
+
 	// Iteriere über alle Pods auf dem Knoten
 	for _, pod := range pods.Items {
+		containerUsage := podUsageByKey[pod.Namespace+"/"+pod.Name]
+		var podContainers []ContainerMetrics
+
 		// Iteriere über alle Container in jedem Pod
 		for _, container := range pod.Spec.Containers {
 			requests := container.Resources.Requests
@@ -560,59 +989,155 @@ func calculateNodeMetrics(clientset *kubernetes.Clientset, metricsClient *metric
 			nodeRequestedMem.Add(requests[v1.ResourceMemory])
 			nodeLimitsCPU.Add(limits[v1.ResourceCPU])
 			nodeLimitsMem.Add(limits[v1.ResourceMemory])
+			nodeRequestedEphemeral.Add(requests[v1.ResourceEphemeralStorage])
+			nodeLimitsEphemeral.Add(limits[v1.ResourceEphemeralStorage])
+
+			usage := containerUsage[container.Name]
+			usedCPU := usage[v1.ResourceCPU]
+			usedMem := usage[v1.ResourceMemory]
+			reqCPU := requests[v1.ResourceCPU]
+			reqMem := requests[v1.ResourceMemory]
+			limCPU := limits[v1.ResourceCPU]
+			limMem := limits[v1.ResourceMemory]
+
+			containerMetrics := ContainerMetrics{
+				Name:                     container.Name,
+				RequestedCPU:             convertCpuStr(reqCPU),
+				RequestedMemory:          convertMemStr(reqMem),
+				LimitsCPU:                convertCpuStr(limCPU),
+				LimitsMemory:             convertMemStr(limMem),
+				UsedCPU:                  convertCpuStr(usedCPU),
+				UsedMemory:               convertMemStr(usedMem),
+				CPUUtilRequests:          utilizationPercent(usedCPU, reqCPU),
+				CPUUtilLimits:            utilizationPercent(usedCPU, limCPU),
+				MemUtilRequests:          utilizationPercent(usedMem, reqMem),
+				MemUtilLimits:            utilizationPercent(usedMem, limMem),
+				EphemeralStorageRequests: convertMemStr(requests[v1.ResourceEphemeralStorage]),
+				EphemeralStorageLimits:   convertMemStr(limits[v1.ResourceEphemeralStorage]),
+			}
+			if !podMetricsAvailable {
+				containerMetrics.UsedCPU = "n/a"
+				containerMetrics.UsedMemory = "n/a"
+				containerMetrics.CPUUtilRequests, containerMetrics.CPUUtilLimits = "n/a", "n/a"
+				containerMetrics.MemUtilRequests, containerMetrics.MemUtilLimits = "n/a", "n/a"
+			}
+			podContainers = append(podContainers, containerMetrics)
 		}
+
+		podMetricsOnNode = append(podMetricsOnNode, PodMetrics{
+			Name:       pod.Name,
+			Namespace:  pod.Namespace,
+			Containers: podContainers,
+		})
 	}
 
-	// Hole die aktuellen Nutzungsmetriken für den Knoten
+	// Hole die aktuellen Nutzungsmetriken für den Knoten. Ist der metrics-server nicht
+	// installiert/erreichbar (z.B. auf einem Cluster ohne metrics-server), werden die Used*-Felder
+	// auf "n/a" gesetzt statt die ganze Berechnung abzubrechen - Capacity/Requests/Limits bleiben
+	// trotzdem verfügbar.
+	nodeMetricsAvailable := true
 	nodeMetrics, err := metricsClient.MetricsV1beta1().NodeMetricses().Get(context.TODO(), node.Name, metav1.GetOptions{})
 	if err != nil {
-		log.Fatalf("Error getting metrics for node %s: %v", node.Name, err)
+		log.Printf("Warning: node metrics unavailable for %s (%v), reporting used values as n/a", node.Name, err)
+		nodeMetricsAvailable = false
+	} else {
+		// Addiere die aktuellen Nutzungsmetriken zu den Gesamtsummen
+		nodeUsedCPU.Add(*nodeMetrics.Usage.Cpu())
+		nodeUsedMem.Add(*nodeMetrics.Usage.Memory())
 	}
 
-	// Addiere die aktuellen Nutzungsmetriken zu den Gesamtsummen
-	nodeUsedCPU.Add(*nodeMetrics.Usage.Cpu())
-	nodeUsedMem.Add(*nodeMetrics.Usage.Memory())
-
 	// Hole die physische Kapazität des Knotens
 	physicalCPU := node.Status.Capacity[v1.ResourceCPU]
 	physicalMemory := node.Status.Capacity[v1.ResourceMemory]
+	physicalEphemeral := node.Status.Capacity[v1.ResourceEphemeralStorage]
+
+	usedCPUStr, usedMemStr := convertCpuStr(nodeUsedCPU), convertMemStr(nodeUsedMem)
+	cpuUtilCapacity, cpuUtilRequests, cpuUtilLimits := utilizationPercent(nodeUsedCPU, physicalCPU), utilizationPercent(nodeUsedCPU, nodeRequestedCPU), utilizationPercent(nodeUsedCPU, nodeLimitsCPU)
+	memUtilCapacity, memUtilRequests, memUtilLimits := utilizationPercent(nodeUsedMem, physicalMemory), utilizationPercent(nodeUsedMem, nodeRequestedMem), utilizationPercent(nodeUsedMem, nodeLimitsMem)
+	// metrics.k8s.io liefert keine ephemeral-storage-Nutzung, daher bleibt UsedEphemeralStorage immer
+	// "n/a" - siehe Doc-Kommentar an NodeMetrics.EphemeralStorageCapacity.
+	usedEphemeralStr, ephemeralUtilCapacity := "n/a", "n/a"
+	if !nodeMetricsAvailable {
+		usedCPUStr, usedMemStr = "n/a", "n/a"
+		cpuUtilCapacity, cpuUtilRequests, cpuUtilLimits = "n/a", "n/a", "n/a"
+		memUtilCapacity, memUtilRequests, memUtilLimits = "n/a", "n/a", "n/a"
+	}
 
 	// Erstelle und gib die NodeMetrics-Struktur zurück
 	return NodeMetrics{
-		Name:            node.Name,
-		NodeType:        actualNodeType,
-		PhysicalCPU:     physicalCPU.String(),
-		PhysicalMemory:  convertMemStr(physicalMemory),
-		RequestedCPU:    convertCpuStr(nodeRequestedCPU),
-		RequestedMemory: convertMemStr(nodeRequestedMem),
-		LimitsCPU:       convertCpuStr(nodeLimitsCPU),
-		LimitsMemory:    convertMemStr(nodeLimitsMem),
-		UsedCPU:         convertCpuStr(nodeUsedCPU),
-		UsedMemory:      convertMemStr(nodeUsedMem),
-		Labels:          labels, // Labels hinzufügen
+		Name:                             node.Name,
+		NodeType:                         actualNodeType,
+		PhysicalCPU:                      physicalCPU.String(),
+		PhysicalMemory:                   convertMemStr(physicalMemory),
+		RequestedCPU:                     convertCpuStr(nodeRequestedCPU),
+		RequestedMemory:                  convertMemStr(nodeRequestedMem),
+		LimitsCPU:                        convertCpuStr(nodeLimitsCPU),
+		LimitsMemory:                     convertMemStr(nodeLimitsMem),
+		UsedCPU:                          usedCPUStr,
+		UsedMemory:                       usedMemStr,
+		Labels:                           labels, // Labels hinzufügen
+		CPUUtilCapacity:                  cpuUtilCapacity,
+		CPUUtilRequests:                  cpuUtilRequests,
+		CPUUtilLimits:                    cpuUtilLimits,
+		MemUtilCapacity:                  memUtilCapacity,
+		MemUtilRequests:                  memUtilRequests,
+		MemUtilLimits:                    memUtilLimits,
+		PhysicalCPUQuantity:              physicalCPU,
+		RequestedCPUQuantity:             nodeRequestedCPU,
+		LimitsCPUQuantity:                nodeLimitsCPU,
+		UsedCPUQuantity:                  nodeUsedCPU,
+		PhysicalMemoryQuantity:           physicalMemory,
+		RequestedMemoryQuantity:          nodeRequestedMem,
+		LimitsMemoryQuantity:             nodeLimitsMem,
+		UsedMemoryQuantity:               nodeUsedMem,
+		MetricsAvailable:                 nodeMetricsAvailable,
+		EphemeralStorageCapacity:         convertMemStr(physicalEphemeral),
+		EphemeralStorageRequests:         convertMemStr(nodeRequestedEphemeral),
+		EphemeralStorageLimits:           convertMemStr(nodeLimitsEphemeral),
+		EphemeralStorageCapacityQuantity: physicalEphemeral,
+		EphemeralStorageRequestsQuantity: nodeRequestedEphemeral,
+		EphemeralStorageLimitsQuantity:   nodeLimitsEphemeral,
+		UsedEphemeralStorage:             usedEphemeralStr,
+		EphemeralStorageUtilCapacity:     ephemeralUtilCapacity,
+		Pods:                             podMetricsOnNode,
+	}, nil
+}
+
+// utilizationPercent berechnet used/denom als Prozentwert mit einer Nachkommastelle, analog zu den
+// kubeletstats-Utilization-Metriken (k8s.container.cpu.node.utilization, k8s.pod.cpu.limit_utilization, ...).
+//
+// Ist denom 0 (z.B. weil kein Limit gesetzt ist), wird "n/a" statt eines Divisions-durch-0-Ergebnisses
+// zurückgegeben.
+func utilizationPercent(used, denom resource.Quantity) string {
+	if denom.IsZero() {
+		return "n/a"
 	}
+	ratio := float64(used.MilliValue()) / float64(denom.MilliValue())
+	return fmt.Sprintf("%.1f%%", ratio*100)
 }
 
 // parseQuantity parst einen Ressourcen-String in eine resource.Quantity-Struktur.
 //
 // Diese Funktion wird verwendet, um einen Ressourcen-String (z.B. "500m", "1Gi") in eine resource.Quantity-Struktur zu parsen.
-// Wenn ein Fehler beim Parsen auftritt, beendet die Funktion das Programm mit einem log.Fatalf-Aufruf.
+// Ein Parse-Fehler wird an den Aufrufer zurückgegeben statt das Programm zu beenden, damit ein einzelner
+// fehlerhafter Wert nicht den ganzen Server abschießt.
 //
 // Parameter:
 // - quantityStr: Ein String, der die Ressource darstellt.
 //
 // Rückgabewerte:
 // - resource.Quantity: Die geparste resource.Quantity-Struktur.
+// - error: Ein Fehlerobjekt, falls quantityStr sich nicht parsen lässt.
 //
 // Beispiel:
 //
-//	quantity := parseQuantity("500m")
-func parseQuantity(quantityStr string) resource.Quantity {
+//	quantity, err := parseQuantity("500m")
+func parseQuantity(quantityStr string) (resource.Quantity, error) {
 	quantity, err := resource.ParseQuantity(quantityStr)
 	if err != nil {
-		log.Fatalf("Error parsing quantity: %v", err)
+		return resource.Quantity{}, fmt.Errorf("error parsing quantity %q: %w", quantityStr, err)
 	}
-	return quantity
+	return quantity, nil
 }
 
 // convertCpuStr konvertiert eine resource.Quantity in einen String, der die CPU in Kernen darstellt.
@@ -651,6 +1176,21 @@ func convertMemStr(quantity resource.Quantity) string {
 	return fmt.Sprintf("%dGi", convertToGiga(&quantity).Value())
 }
 
+// quantityCPUCores liefert den Wert einer CPU-resource.Quantity als float64 in Kernen, mit derselben
+// Rundung wie convertCpuStr. Wird von buildClusterMetricsWorkbook verwendet, um die Excel-Zellen
+// direkt aus den *Quantity-Feldern von NodeMetrics/ClusterMetrics zu befüllen, statt die von
+// convertCpuStr formatierten Strings wieder zurückzuparsen.
+func quantityCPUCores(quantity resource.Quantity) float64 {
+	return float64(convertToMilli(&quantity).Value()) / 1000.0
+}
+
+// quantityMemGiB liefert den Wert einer Speicher-resource.Quantity als float64 in GiB, mit derselben
+// Rundung wie convertMemStr. Wird von buildClusterMetricsWorkbook aus demselben Grund wie
+// quantityCPUCores verwendet.
+func quantityMemGiB(quantity resource.Quantity) float64 {
+	return float64(convertToGiga(&quantity).Value())
+}
+
 // convertToMilli konvertiert eine resource.Quantity in Milli-Einheiten.
 //
 // Diese Funktion wird verwendet, um eine resource.Quantity in Milli-Einheiten zu konvertieren.
@@ -713,16 +1253,23 @@ func sortNodeMetricsByName(nodes []NodeMetrics) {
 // Parameter:
 // - w: Der HTTP-Response-Writer, in den die gerenderte HTML-Vorlage geschrieben wird.
 // - clusterMetrics: Eine Struktur, die die Cluster-Metriken enthält, die in der HTML-Vorlage angezeigt werden sollen.
+// - include: Steuert, ob die Ephemeral-Storage-Spalten mit angezeigt werden. Pro-Kern-Spalten gibt es
+//   nicht; die Vorlage weist nur darauf hin, dass metrics.k8s.io keine Pro-Kern-Werte liefert.
 //
 // Beispiel:
 //
-//	renderTemplate(responseWriter, clusterMetrics)
+//	renderTemplate(responseWriter, clusterMetrics, includeOptions{})
+//
+// Rückgabewerte:
+//   - error: Ein Fehlerobjekt, falls das Ausführen der Vorlage fehlschlägt (z.B. weil der Client die
+//     Verbindung mitten im Schreiben beendet hat). Der Aufrufer entscheidet, wie darauf reagiert wird,
+//     statt dass ein einzelner fehlerhafter Request den ganzen Server beendet.
 //
 // Ablauf:
 // 1. Definiert und parst die HTML-Vorlage.
 // 2. Führt die Vorlage mit den übergebenen Cluster-Metriken aus und schreibt das Ergebnis in den HTTP-Response-Writer.
-// 3. Loggt einen Fehler, falls das Ausführen der Vorlage fehlschlägt.
-func renderTemplate(w http.ResponseWriter, clusterMetrics ClusterMetrics) {
+// 3. Gibt einen etwaigen Fehler beim Ausführen der Vorlage an den Aufrufer zurück.
+func renderTemplate(w http.ResponseWriter, clusterMetrics ClusterMetrics, include includeOptions) error {
 	// Definiere und parse die HTML-Vorlage
 	tmpl := template.Must(template.New("clusterMetrics").Parse(`
         <!DOCTYPE html>
@@ -766,6 +1313,19 @@ func renderTemplate(w http.ResponseWriter, clusterMetrics ClusterMetrics) {
                     <th>Requested Memory (Gi)</th>
                     <th>Limits Memory (Gi)</th>
                     <th>Used Memory (Gi)</th>
+                    <th>CPU Util/Cap</th>
+                    <th>CPU Util/Req</th>
+                    <th>CPU Util/Limit</th>
+                    <th>Mem Util/Cap</th>
+                    <th>Mem Util/Req</th>
+                    <th>Mem Util/Limit</th>
+                    {{ if .Include.Ephemeral }}
+                    <th>Ephemeral Capacity (Gi)</th>
+                    <th>Ephemeral Requests (Gi)</th>
+                    <th>Ephemeral Limits (Gi)</th>
+                    <th>Ephemeral Used</th>
+                    <th>Ephemeral Util/Cap</th>
+                    {{ end }}
                 </tr>
                 {{ range .Nodes }}
                 <tr title="{{ range $key, $value := .Labels }}{{ $key }}: {{ $value }}&#10;{{ end }}">
@@ -779,6 +1339,19 @@ func renderTemplate(w http.ResponseWriter, clusterMetrics ClusterMetrics) {
                     <td class="requested-metrics center-text">{{ .RequestedMemory }}</td>
                     <td class="limited-metrics center-text">{{ .LimitsMemory }}</td>
                     <td class="used-metrics center-text">{{ .UsedMemory }}</td>
+                    <td class="used-metrics center-text">{{ .CPUUtilCapacity }}</td>
+                    <td class="used-metrics center-text">{{ .CPUUtilRequests }}</td>
+                    <td class="used-metrics center-text">{{ .CPUUtilLimits }}</td>
+                    <td class="used-metrics center-text">{{ .MemUtilCapacity }}</td>
+                    <td class="used-metrics center-text">{{ .MemUtilRequests }}</td>
+                    <td class="used-metrics center-text">{{ .MemUtilLimits }}</td>
+                    {{ if $.Include.Ephemeral }}
+                    <td class="physical-metrics center-text">{{ .EphemeralStorageCapacity }}</td>
+                    <td class="requested-metrics center-text">{{ .EphemeralStorageRequests }}</td>
+                    <td class="limited-metrics center-text">{{ .EphemeralStorageLimits }}</td>
+                    <td class="used-metrics center-text">{{ .UsedEphemeralStorage }}</td>
+                    <td class="used-metrics center-text">{{ .EphemeralStorageUtilCapacity }}</td>
+                    {{ end }}
                 </tr>
                 {{ end }}
                 <tr class="total-row">
@@ -792,20 +1365,31 @@ func renderTemplate(w http.ResponseWriter, clusterMetrics ClusterMetrics) {
                     <th class="requested-metrics">{{ .TotalRequestedMemory }}</th>
                     <th class="limited-metrics">{{ .TotalLimitsMemory }}</th>
                     <th class="used-metrics">{{ .TotalUsedMemory }}</th>
+                    <th colspan="6"></th>
+                    {{ if .Include.Ephemeral }}
+                    <th class="physical-metrics">{{ .TotalEphemeralStorageCapacity }}</th>
+                    <th class="requested-metrics">{{ .TotalEphemeralStorageRequests }}</th>
+                    <th class="limited-metrics">{{ .TotalEphemeralStorageLimits }}</th>
+                    <th colspan="2"></th>
+                    {{ end }}
                 </tr>
             </table>
             <p>optional params worker: /metrics/?node-type=worker; infra: /metrics?node-type=infra; master: /metrics?node-type=master; all: /metrics</p>
+            <p>optional extra columns: /metrics?include=ephemeral</p>
+            <p>per-core CPU utilization is not available: metrics.k8s.io only reports an aggregated CPU usage value per node, not a per-core breakdown.</p>
+            <p>optional output format: /metrics?format=json|yaml|csv|prom (default: html)</p>
             <p><a href="/download/excel">Download Excel</a></p>
+            <p><a href="/prometheus">Prometheus metrics</a></p>
         </body>
         </html>
     `))
 
-	// Führe die Vorlage mit den übergebenen Cluster-Metriken aus und schreibe das Ergebnis in den HTTP-Response-Writer
-	err := tmpl.Execute(w, clusterMetrics)
-	if err != nil {
-		// Logge einen Fehler, falls das Ausführen der Vorlage fehlschlägt
-		log.Fatalf("Error executing template: %v", err)
+	// Führe die Vorlage mit den übergebenen Cluster-Metriken und includeOptions aus und schreibe das
+	// Ergebnis in den HTTP-Response-Writer
+	if err := tmpl.Execute(w, templateData{ClusterMetrics: clusterMetrics, Include: include}); err != nil {
+		return fmt.Errorf("error executing template: %v", err)
 	}
+	return nil
 }
 
 // printASCIITable druckt die Cluster-Metriken in einer ASCII-Tabelle auf die Standardausgabe.
@@ -826,205 +1410,75 @@ func renderTemplate(w http.ResponseWriter, clusterMetrics ClusterMetrics) {
 // 3. Iteriert über alle Knoten und druckt deren Metriken.
 // 4. Druckt die Gesamtsummen der Metriken.
 // 5. Flusht den Tabwriter, um sicherzustellen, dass alle Daten geschrieben werden.
-func printASCIITable(clusterMetrics ClusterMetrics) {
+func printASCIITable(clusterMetrics ClusterMetrics, include includeOptions) {
+	printASCIITableTo(os.Stdout, clusterMetrics, include)
+}
+
+// printASCIITableTo rendert die ASCII-Tabelle wie printASCIITable, aber nach einen beliebigen
+// io.Writer statt fest nach os.Stdout - wird vom asciiRenderer (siehe renderers.go) genutzt, um die
+// Tabelle auch als HTTP-Response-Body ausliefern zu können.
+func printASCIITableTo(out io.Writer, clusterMetrics ClusterMetrics, include includeOptions) {
 	// Erstelle einen neuen Tabwriter, um die Tabelle zu formatieren
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.Debug)
+	w := tabwriter.NewWriter(out, 0, 0, 1, ' ', tabwriter.Debug)
 
 	// Drucke die Kopfzeile der Tabelle
-	fmt.Fprintln(w, "Node\t Node Type\t Physical CPU\t Requested CPU\t Limits CPU\t Used CPU\t Physical Memory (Gi)\t Requested Memory (Gi)\t Limits Memory (Gi)\t Used Memory (Gi)\t")
+	header := "Node\t Node Type\t Physical CPU\t Requested CPU\t Limits CPU\t Used CPU\t Physical Memory (Gi)\t Requested Memory (Gi)\t Limits Memory (Gi)\t Used Memory (Gi)\t CPU Util/Cap\t CPU Util/Req\t CPU Util/Limit\t Mem Util/Cap\t Mem Util/Req\t Mem Util/Limit\t"
+	if include.Ephemeral {
+		header += " Ephemeral Capacity (Gi)\t Ephemeral Requests (Gi)\t Ephemeral Limits (Gi)\t Ephemeral Used\t Ephemeral Util/Cap\t"
+	}
+	fmt.Fprintln(w, header)
 
 	// Iteriere über alle Knoten und drucke deren Metriken
 	for _, node := range clusterMetrics.Nodes {
-		fmt.Fprintf(w, "%s\t %s\t %s\t %s\t %s\t %s\t %s\t %s\t %s\t %s\t\n",
-			node.Name, node.NodeType, node.PhysicalCPU, node.RequestedCPU, node.LimitsCPU, node.UsedCPU, node.PhysicalMemory, node.RequestedMemory, node.LimitsMemory, node.UsedMemory)
+		fmt.Fprintf(w, "%s\t %s\t %s\t %s\t %s\t %s\t %s\t %s\t %s\t %s\t %s\t %s\t %s\t %s\t %s\t %s\t",
+			node.Name, node.NodeType, node.PhysicalCPU, node.RequestedCPU, node.LimitsCPU, node.UsedCPU, node.PhysicalMemory, node.RequestedMemory, node.LimitsMemory, node.UsedMemory,
+			node.CPUUtilCapacity, node.CPUUtilRequests, node.CPUUtilLimits, node.MemUtilCapacity, node.MemUtilRequests, node.MemUtilLimits)
+		if include.Ephemeral {
+			fmt.Fprintf(w, " %s\t %s\t %s\t %s\t %s\t",
+				node.EphemeralStorageCapacity, node.EphemeralStorageRequests, node.EphemeralStorageLimits, node.UsedEphemeralStorage, node.EphemeralStorageUtilCapacity)
+		}
+		fmt.Fprintln(w)
 	}
 
 	// Drucke die Gesamtsummen der Metriken
-	fmt.Fprintf(w, "Total\t\t %s\t %s\t %s\t %s\t %s\t %s\t %s\t %s\t\n",
+	fmt.Fprintf(w, "Total\t\t %s\t %s\t %s\t %s\t %s\t %s\t %s\t %s\t",
 		clusterMetrics.TotalPhysicalCPU, clusterMetrics.TotalRequestedCPU, clusterMetrics.TotalLimitsCPU, clusterMetrics.TotalUsedCPU,
 		clusterMetrics.TotalPhysicalMemory, clusterMetrics.TotalRequestedMemory, clusterMetrics.TotalLimitsMemory, clusterMetrics.TotalUsedMemory)
+	if include.Ephemeral {
+		fmt.Fprintf(w, " %s\t %s\t %s\t", clusterMetrics.TotalEphemeralStorageCapacity, clusterMetrics.TotalEphemeralStorageRequests, clusterMetrics.TotalEphemeralStorageLimits)
+	}
+	fmt.Fprintln(w)
 
 	// Flushe den Tabwriter, um sicherzustellen, dass alle Daten geschrieben werden
 	w.Flush()
 }
 
-// generateExcelFile erstellt eine Excel-Datei mit den Cluster-Metriken und speichert sie auf dem Server.
-//
-// Diese Funktion wird verwendet, um die Cluster-Metriken in eine Excel-Datei zu konvertieren und die Datei auf dem Server zu speichern.
-// Die Excel-Datei enthält eine Tabelle mit den Metriken der einzelnen Knoten sowie die Gesamtsummen der Metriken.
+// downloadExcelHandler baut den HTTP-Handler, der die Excel-Arbeitsmappe (siehe excel_workbook.go) zum
+// Download bereitstellt. historyStore wird durchgereicht, damit das "History"-Arbeitsblatt Zeitreihen
+// anzeigen kann; siehe buildClusterMetricsWorkbook.
 //
-// Parameter:
-// - filePath: Der Pfad, unter dem die Excel-Datei gespeichert werden soll.
-// - clusterMetrics: Eine Struktur, die die Cluster-Metriken enthält, die in der Excel-Datei angezeigt werden sollen.
+// Die Arbeitsmappe wird über den excelRenderer (siehe renderers.go) direkt in den
+// HTTP-Response-Writer geschrieben, ohne den Umweg über eine temporäre Datei.
 //
 // Beispiel:
 //
-//	err := generateExcelFile("/path/to/file.xlsx", clusterMetrics)
-//	if err != nil {
-//		log.Fatalf("Error generating Excel file: %v", err)
-//	}
+//	http.HandleFunc("/download/excel", downloadExcelHandler(historyStore))
 //
 // Ablauf:
-// 1. Erstellt eine neue Excel-Datei.
-// 2. Fügt ein neues Arbeitsblatt hinzu.
-// 3. Fügt die Kopfzeile der Tabelle hinzu.
-// 4. Fügt die Metriken der einzelnen Knoten zur Tabelle hinzu.
-// 5. Fügt die Gesamtsummen der Metriken zur Tabelle hinzu.
-// 6. Speichert die Excel-Datei auf dem Server.
-func generateExcelFile(filePath string, clusterMetrics ClusterMetrics) error {
-	// Erstelle eine neue Excel-Datei
-	file := xlsx.NewFile()
-	sheet, err := file.AddSheet("Cluster Metrics")
-	if err != nil {
-		return err
-	}
-
-	// Füge die Kopfzeile der Tabelle hinzu
-	headerRow := sheet.AddRow()
-	headers := []string{"Node", "Node Type", "Physical CPU (core)", "Requested CPU (core)", "Limits CPU (core)", "Used CPU (core)", "Physical Memory (Gi)", "Requested Memory (Gi)", "Limits Memory (Gi)", "Used Memory (Gi)"}
-	for _, header := range headers {
-		cell := headerRow.AddCell()
-		cell.Value = header
-	}
-
-	// Füge die Metriken der einzelnen Knoten zur Tabelle hinzu
-	for _, node := range clusterMetrics.Nodes {
-		row := sheet.AddRow()
-		row.AddCell().Value = node.Name
-		row.AddCell().Value = node.NodeType
-		PhysicalCPU, err := strconv.ParseFloat(strings.Replace(node.PhysicalCPU, "Gi", "", -1), 64)
-		if err != nil {
-			log.Fatalf("Error converting PhysicalCPU to float64: %v", err)
-		}
-		RequestedCPU, err := strconv.ParseFloat(strings.Replace(node.RequestedCPU, "Gi", "", -1), 64)
-		if err != nil {
-			log.Fatalf("Error converting RequestedCPU to float64: %v", err)
-		}
-		LimitsCPU, err := strconv.ParseFloat(strings.Replace(node.LimitsCPU, "Gi", "", -1), 64)
-		if err != nil {
-			log.Fatalf("Error converting LimitsCPU to float64: %v", err)
-		}
-		UsedCPU, err := strconv.ParseFloat(strings.Replace(node.UsedCPU, "Gi", "", -1), 64)
-		if err != nil {
-			log.Fatalf("Error converting UsedCPU to float64: %v", err)
-		}
-		PhysicalMemory, err := strconv.ParseFloat(strings.Replace(node.PhysicalMemory, "Gi", "", -1), 64)
-		if err != nil {
-			log.Fatalf("Error converting PhysicalMemory to float64: %v", err)
-		}
-		RequestedMemory, err := strconv.ParseFloat(strings.Replace(node.RequestedMemory, "Gi", "", -1), 64)
-		if err != nil {
-			log.Fatalf("Error converting RequestedMemory to float64: %v", err)
+//  1. Setzt den Content-Type und die Content-Disposition-Header, um den Download der Excel-Datei zu initiieren.
+//  2. Rendert die Arbeitsmappe über excelRenderer direkt in den HTTP-Response-Writer.
+//  3. Loggt einen Fehler und antwortet mit einem strukturierten JSON-Fehlerobjekt (siehe writeJSONError
+//     in renderers.go), falls das Rendern fehlschlägt, statt den Server abzuschießen.
+func downloadExcelHandler(historyStore *history.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Setze den Content-Type und die Content-Disposition-Header
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		w.Header().Set("Content-Disposition", "attachment; filename=cluster_metrics.xlsx")
+
+		if err := (excelRenderer{store: historyStore}).Render(w, clusterMetrics); err != nil {
+			log.Printf("Error generating Excel file: %v", err)
+			writeJSONError(w, http.StatusInternalServerError, "Error generating Excel file")
+			return
 		}
-		LimitsMemory, err := strconv.ParseFloat(strings.Replace(node.LimitsMemory, "Gi", "", -1), 64)
-		if err != nil {
-			log.Fatalf("Error converting LimitsMemory to float64: %v", err)
-		}
-		UsedMemory, err := strconv.ParseFloat(strings.Replace(node.UsedMemory, "Gi", "", -1), 64)
-		if err != nil {
-			log.Fatalf("Error converting UsedMemory to float64: %v", err)
-		}
-		row.AddCell().SetFloat(PhysicalCPU)
-		row.AddCell().SetFloat(RequestedCPU)
-		row.AddCell().SetFloat(LimitsCPU)
-		row.AddCell().SetFloat(UsedCPU)
-		row.AddCell().SetFloat(PhysicalMemory)
-		row.AddCell().SetFloat(RequestedMemory)
-		row.AddCell().SetFloat(LimitsMemory)
-		row.AddCell().SetFloat(UsedMemory)
-	}
-
-	// Füge die Gesamtsummen der Metriken zur Tabelle hinzu
-	totalRow := sheet.AddRow()
-	totalRow.AddCell().Value = "Total"
-	totalRow.AddCell().Value = ""
-	TotalPhysicalCPU, err := strconv.ParseFloat(strings.Replace(clusterMetrics.TotalPhysicalCPU, "Gi", "", -1), 64)
-	if err != nil {
-		log.Fatalf("Error converting TotalPhysicalCPU to float64: %v", err)
-	}
-	TotalRequestedCPU, err := strconv.ParseFloat(strings.Replace(clusterMetrics.TotalRequestedCPU, "Gi", "", -1), 64)
-	if err != nil {
-		log.Fatalf("Error converting TotalRequestedCPU to float64: %v", err)
-	}
-	TotalLimitsCPU, err := strconv.ParseFloat(strings.Replace(clusterMetrics.TotalLimitsCPU, "Gi", "", -1), 64)
-	if err != nil {
-		log.Fatalf("Error converting TotalLimitsCPU to float64: %v", err)
-	}
-	TotalUsedCPU, err := strconv.ParseFloat(strings.Replace(clusterMetrics.TotalUsedCPU, "Gi", "", -1), 64)
-	if err != nil {
-		log.Fatalf("Error converting TotalUsedCPU to float64: %v", err)
-	}
-	TotalPhysicalMemory, err := strconv.ParseFloat(strings.Replace(clusterMetrics.TotalPhysicalMemory, "Gi", "", -1), 64)
-	if err != nil {
-		log.Fatalf("Error converting TotalPhysicalMemory to float64: %v", err)
-	}
-	TotalRequestedMemory, err := strconv.ParseFloat(strings.Replace(clusterMetrics.TotalRequestedMemory, "Gi", "", -1), 64)
-	if err != nil {
-		log.Fatalf("Error converting TotalRequestedMemory to float64: %v", err)
-	}
-	TotalLimitsMemory, err := strconv.ParseFloat(strings.Replace(clusterMetrics.TotalLimitsMemory, "Gi", "", -1), 64)
-	if err != nil {
-		log.Fatalf("Error converting TotalLimitsMemory to float64: %v", err)
-	}
-	TotalUsedMemory, err := strconv.ParseFloat(strings.Replace(clusterMetrics.TotalUsedMemory, "Gi", "", -1), 64)
-	if err != nil {
-		log.Fatalf("Error converting TotalUsedMemory to float64: %v", err)
-	}
-	totalRow.AddCell().SetFloat(TotalPhysicalCPU)
-	totalRow.AddCell().SetFloat(TotalRequestedCPU)
-	totalRow.AddCell().SetFloat(TotalLimitsCPU)
-	totalRow.AddCell().SetFloat(TotalUsedCPU)
-	totalRow.AddCell().SetFloat(TotalPhysicalMemory)
-	totalRow.AddCell().SetFloat(TotalRequestedMemory)
-	totalRow.AddCell().SetFloat(TotalLimitsMemory)
-	totalRow.AddCell().SetFloat(TotalUsedMemory)
-
-	// Speichere die Excel-Datei auf dem Server
-	err = file.Save(filePath)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// downloadExcelHandler ist ein HTTP-Handler, der die Excel-Datei zum Download bereitstellt.
-//
-// Dieser Handler wird verwendet, um die Excel-Datei mit den Cluster-Metriken zum Download bereitzustellen.
-// Die Excel-Datei wird auf dem Server gespeichert und kann über diesen Handler heruntergeladen werden.
-//
-// Beispiel:
-//
-//	http.HandleFunc("/download/excel", downloadExcelHandler)
-//
-// Ablauf:
-// 1. Setzt den Content-Type und die Content-Disposition-Header, um den Download der Excel-Datei zu initiieren.
-// 2. Öffnet die Excel-Datei und kopiert ihren Inhalt in den HTTP-Response-Writer.
-// 3. Loggt einen Fehler, falls das Öffnen oder Kopieren der Datei fehlschlägt.
-func downloadExcelHandler(w http.ResponseWriter, r *http.Request) {
-	filePath := "/tmp/file.xlsx" // Pfad zur gespeicherten Excel-Datei
-
-	// Setze den Content-Type und die Content-Disposition-Header
-	w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
-	w.Header().Set("Content-Disposition", "attachment; filename=cluster_metrics.xlsx")
-
-	// Beispielaufruf der generateExcelFile-Funktion
-	err := generateExcelFile(filePath, clusterMetrics)
-	if err != nil {
-		log.Fatalf("Error generating Excel file: %v", err)
-	}
-	// Öffne die Excel-Datei
-	file, err := os.Open(filePath)
-	if err != nil {
-		http.Error(w, "Unable to open Excel file", http.StatusInternalServerError)
-		return
-	}
-	defer file.Close()
-
-	// Kopiere den Inhalt der Excel-Datei in den HTTP-Response-Writer
-	_, err = io.Copy(w, file)
-	if err != nil {
-		http.Error(w, "Unable to copy Excel file", http.StatusInternalServerError)
-		return
 	}
 }
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// TestUtilizationPercentZeroDenominator covers the "n/a"/0-capacity edge case: a node or container
+// without a request/limit/capacity set must not divide by zero.
+func TestUtilizationPercentZeroDenominator(t *testing.T) {
+	used := resource.MustParse("500m")
+	var zero resource.Quantity
+
+	if got := utilizationPercent(used, zero); got != "n/a" {
+		t.Fatalf("expected %q for zero denominator, got %q", "n/a", got)
+	}
+}
+
+// TestUtilizationPercentComputesRatio covers the normal path.
+func TestUtilizationPercentComputesRatio(t *testing.T) {
+	used := resource.MustParse("500m")
+	denom := resource.MustParse("1")
+
+	if got, want := utilizationPercent(used, denom), "50.0%"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/jaegdi/cluster-resources/internal/cache"
+)
+
+// calculateClusterMetricsFromCache berechnet die ClusterMetrics aus dem Informer/Watch-basierten
+// ClusterCache statt aus direkten Nodes().List/Pods().List-Aufrufen. Dadurch kostet ein Request nur
+// noch einen Walk über bereits lokal gehaltenen Daten, unabhängig davon wie viele Pods der Cluster hat.
+//
+// Die Funktion spiegelt calculateClusterMetrics/calculateNodeMetrics, liest aber aus clusterCache
+// statt aus clientset/metricsClient.
+func calculateClusterMetricsFromCache(clusterCache *cache.ClusterCache, nodeType string) (ClusterMetrics, error) {
+	nodes, err := clusterCache.ListNodes()
+	if err != nil {
+		return ClusterMetrics{}, fmt.Errorf("error listing nodes from cache: %v", err)
+	}
+
+	var totalPhysicalCPU, totalPhysicalMemory, totalRequestedCPU, totalRequestedMem, totalLimitsCPU, totalLimitsMem, totalUsedCPU, totalUsedMem resource.Quantity
+	var totalEphemeralCapacity, totalEphemeralRequests, totalEphemeralLimits resource.Quantity
+	var nodeMetricsList []NodeMetrics
+
+	var wg sync.WaitGroup
+	nodeMetricsChan := make(chan NodeMetrics, len(nodes))
+
+	for _, node := range nodes {
+		if _, isNodeType := node.Labels[fmt.Sprintf("node-role.kubernetes.io/%s", nodeType)]; isNodeType || nodeType == "all" {
+			wg.Add(1)
+			go func(node *v1.Node) {
+				defer wg.Done()
+				nodeMetricsChan <- calculateNodeMetricsFromCache(clusterCache, node, nodeType)
+			}(node)
+		}
+	}
+
+	wg.Wait()
+	close(nodeMetricsChan)
+
+	for nodeMetrics := range nodeMetricsChan {
+		nodeMetricsList = append(nodeMetricsList, nodeMetrics)
+		totalPhysicalCPU.Add(resource.MustParse(nodeMetrics.PhysicalCPU))
+		totalPhysicalMemory.Add(resource.MustParse(nodeMetrics.PhysicalMemory))
+		totalRequestedCPU.Add(resource.MustParse(nodeMetrics.RequestedCPU))
+		totalRequestedMem.Add(resource.MustParse(nodeMetrics.RequestedMemory))
+		totalLimitsCPU.Add(resource.MustParse(nodeMetrics.LimitsCPU))
+		totalLimitsMem.Add(resource.MustParse(nodeMetrics.LimitsMemory))
+		// UsedCPU/UsedMemory are formatted strings that read "n/a" when the node has no metrics
+		// sample yet; sum the raw quantities instead, which are always valid (zero) in that case.
+		totalUsedCPU.Add(nodeMetrics.UsedCPUQuantity)
+		totalUsedMem.Add(nodeMetrics.UsedMemoryQuantity)
+		totalEphemeralCapacity.Add(resource.MustParse(nodeMetrics.EphemeralStorageCapacity))
+		totalEphemeralRequests.Add(resource.MustParse(nodeMetrics.EphemeralStorageRequests))
+		totalEphemeralLimits.Add(resource.MustParse(nodeMetrics.EphemeralStorageLimits))
+	}
+
+	return ClusterMetrics{
+		Nodes:                nodeMetricsList,
+		TotalPhysicalCPU:     convertCpuStr(totalPhysicalCPU),
+		TotalPhysicalMemory:  convertMemStr(totalPhysicalMemory),
+		TotalRequestedCPU:    convertCpuStr(totalRequestedCPU),
+		TotalRequestedMemory: convertMemStr(totalRequestedMem),
+		TotalLimitsCPU:       convertCpuStr(totalLimitsCPU),
+		TotalLimitsMemory:    convertMemStr(totalLimitsMem),
+		TotalUsedCPU:         convertCpuStr(totalUsedCPU),
+		TotalUsedMemory:      convertMemStr(totalUsedMem),
+
+		TotalPhysicalCPUQuantity:     totalPhysicalCPU,
+		TotalPhysicalMemoryQuantity:  totalPhysicalMemory,
+		TotalRequestedCPUQuantity:    totalRequestedCPU,
+		TotalRequestedMemoryQuantity: totalRequestedMem,
+		TotalLimitsCPUQuantity:       totalLimitsCPU,
+		TotalLimitsMemoryQuantity:    totalLimitsMem,
+		TotalUsedCPUQuantity:         totalUsedCPU,
+		TotalUsedMemoryQuantity:      totalUsedMem,
+
+		TotalEphemeralStorageCapacity: convertMemStr(totalEphemeralCapacity),
+		TotalEphemeralStorageRequests: convertMemStr(totalEphemeralRequests),
+		TotalEphemeralStorageLimits:   convertMemStr(totalEphemeralLimits),
+
+		TotalEphemeralStorageCapacityQuantity: totalEphemeralCapacity,
+		TotalEphemeralStorageRequestsQuantity: totalEphemeralRequests,
+		TotalEphemeralStorageLimitsQuantity:   totalEphemeralLimits,
+	}, nil
+}
+
+// calculateNodeMetricsFromCache berechnet die NodeMetrics eines einzelnen Knotens ausschließlich aus
+// dem ClusterCache, inklusive der Node→Pod→Container-Aufschlüsselung in NodeMetrics.Pods (siehe
+// calculateNodeMetrics für den äquivalenten Nicht-Cache-Pfad). Fehlt ein Metrics-Sample (z.B. weil der
+// erste Poll noch läuft), werden die Used*-Felder auf "n/a" gesetzt statt die ganze Berechnung
+// scheitern zu lassen.
+func calculateNodeMetricsFromCache(clusterCache *cache.ClusterCache, node *v1.Node, nodeType string) NodeMetrics {
+	var nodeRequestedCPU, nodeRequestedMem, nodeLimitsCPU, nodeLimitsMem, nodeUsedCPU, nodeUsedMem resource.Quantity
+	var nodeRequestedEphemeral, nodeLimitsEphemeral resource.Quantity
+
+	labels := node.Labels
+	actualNodeType := "unknown"
+	if val, ok := labels["node-role.kubernetes.io/worker"]; ok && val == "" {
+		actualNodeType = "worker"
+	} else if val, ok := labels["node-role.kubernetes.io/master"]; ok && val == "" {
+		actualNodeType = "master"
+	} else if val, ok := labels["node-role.kubernetes.io/infra"]; ok && val == "" {
+		actualNodeType = "infra"
+	}
+
+	pods, err := clusterCache.ListPodsByNode(node.Name)
+	if err != nil {
+		pods = nil
+	}
+
+	var podMetricsOnNode []PodMetrics
+
+	for _, pod := range pods {
+		podMetricsAvailable := false
+		var containerUsage map[string]v1.ResourceList
+		if pm, ok := clusterCache.PodMetrics(pod.Namespace, pod.Name); ok {
+			containerUsage = make(map[string]v1.ResourceList, len(pm.Containers))
+			for _, c := range pm.Containers {
+				containerUsage[c.Name] = c.Usage
+			}
+			podMetricsAvailable = true
+		}
+
+		var podContainers []ContainerMetrics
+		for _, container := range pod.Spec.Containers {
+			requests := container.Resources.Requests
+			limits := container.Resources.Limits
+			nodeRequestedCPU.Add(requests[v1.ResourceCPU])
+			nodeRequestedMem.Add(requests[v1.ResourceMemory])
+			nodeLimitsCPU.Add(limits[v1.ResourceCPU])
+			nodeLimitsMem.Add(limits[v1.ResourceMemory])
+			nodeRequestedEphemeral.Add(requests[v1.ResourceEphemeralStorage])
+			nodeLimitsEphemeral.Add(limits[v1.ResourceEphemeralStorage])
+
+			usage := containerUsage[container.Name]
+			usedCPU := usage[v1.ResourceCPU]
+			usedMem := usage[v1.ResourceMemory]
+			reqCPU := requests[v1.ResourceCPU]
+			reqMem := requests[v1.ResourceMemory]
+			limCPU := limits[v1.ResourceCPU]
+			limMem := limits[v1.ResourceMemory]
+
+			containerMetrics := ContainerMetrics{
+				Name:                     container.Name,
+				RequestedCPU:             convertCpuStr(reqCPU),
+				RequestedMemory:          convertMemStr(reqMem),
+				LimitsCPU:                convertCpuStr(limCPU),
+				LimitsMemory:             convertMemStr(limMem),
+				UsedCPU:                  convertCpuStr(usedCPU),
+				UsedMemory:               convertMemStr(usedMem),
+				CPUUtilRequests:          utilizationPercent(usedCPU, reqCPU),
+				CPUUtilLimits:            utilizationPercent(usedCPU, limCPU),
+				MemUtilRequests:          utilizationPercent(usedMem, reqMem),
+				MemUtilLimits:            utilizationPercent(usedMem, limMem),
+				EphemeralStorageRequests: convertMemStr(requests[v1.ResourceEphemeralStorage]),
+				EphemeralStorageLimits:   convertMemStr(limits[v1.ResourceEphemeralStorage]),
+			}
+			if !podMetricsAvailable {
+				containerMetrics.UsedCPU = "n/a"
+				containerMetrics.UsedMemory = "n/a"
+				containerMetrics.CPUUtilRequests, containerMetrics.CPUUtilLimits = "n/a", "n/a"
+				containerMetrics.MemUtilRequests, containerMetrics.MemUtilLimits = "n/a", "n/a"
+			}
+			podContainers = append(podContainers, containerMetrics)
+		}
+
+		podMetricsOnNode = append(podMetricsOnNode, PodMetrics{
+			Name:       pod.Name,
+			Namespace:  pod.Namespace,
+			Containers: podContainers,
+		})
+	}
+
+	physicalCPU := node.Status.Capacity[v1.ResourceCPU]
+	physicalMemory := node.Status.Capacity[v1.ResourceMemory]
+	physicalEphemeral := node.Status.Capacity[v1.ResourceEphemeralStorage]
+
+	usedCPUStr, usedMemStr := "n/a", "n/a"
+	cpuUtilCapacity := "n/a"
+	metricsAvailable := false
+	if nm, ok := clusterCache.NodeMetrics(node.Name); ok {
+		nodeUsedCPU.Add(*nm.Usage.Cpu())
+		nodeUsedMem.Add(*nm.Usage.Memory())
+		usedCPUStr = convertCpuStr(nodeUsedCPU)
+		usedMemStr = convertMemStr(nodeUsedMem)
+		cpuUtilCapacity = utilizationPercent(nodeUsedCPU, physicalCPU)
+		metricsAvailable = true
+	}
+
+	cpuUtilRequests, cpuUtilLimits := utilizationPercent(nodeUsedCPU, nodeRequestedCPU), utilizationPercent(nodeUsedCPU, nodeLimitsCPU)
+	memUtilCapacity, memUtilRequests, memUtilLimits := utilizationPercent(nodeUsedMem, physicalMemory), utilizationPercent(nodeUsedMem, nodeRequestedMem), utilizationPercent(nodeUsedMem, nodeLimitsMem)
+	if !metricsAvailable {
+		cpuUtilRequests, cpuUtilLimits = "n/a", "n/a"
+		memUtilCapacity, memUtilRequests, memUtilLimits = "n/a", "n/a", "n/a"
+	}
+
+	return NodeMetrics{
+		Name:                             node.Name,
+		NodeType:                         actualNodeType,
+		PhysicalCPU:                      physicalCPU.String(),
+		PhysicalMemory:                   convertMemStr(physicalMemory),
+		RequestedCPU:                     convertCpuStr(nodeRequestedCPU),
+		RequestedMemory:                  convertMemStr(nodeRequestedMem),
+		LimitsCPU:                        convertCpuStr(nodeLimitsCPU),
+		LimitsMemory:                     convertMemStr(nodeLimitsMem),
+		UsedCPU:                          usedCPUStr,
+		UsedMemory:                       usedMemStr,
+		PhysicalCPUQuantity:              physicalCPU,
+		RequestedCPUQuantity:             nodeRequestedCPU,
+		LimitsCPUQuantity:                nodeLimitsCPU,
+		UsedCPUQuantity:                  nodeUsedCPU,
+		PhysicalMemoryQuantity:           physicalMemory,
+		RequestedMemoryQuantity:          nodeRequestedMem,
+		LimitsMemoryQuantity:             nodeLimitsMem,
+		UsedMemoryQuantity:               nodeUsedMem,
+		MetricsAvailable:                 metricsAvailable,
+		Labels:                           labels,
+		CPUUtilCapacity:                  cpuUtilCapacity,
+		CPUUtilRequests:                  cpuUtilRequests,
+		CPUUtilLimits:                    cpuUtilLimits,
+		MemUtilCapacity:                  memUtilCapacity,
+		MemUtilRequests:                  memUtilRequests,
+		MemUtilLimits:                    memUtilLimits,
+		EphemeralStorageCapacity:         convertMemStr(physicalEphemeral),
+		EphemeralStorageRequests:         convertMemStr(nodeRequestedEphemeral),
+		EphemeralStorageLimits:           convertMemStr(nodeLimitsEphemeral),
+		EphemeralStorageCapacityQuantity: physicalEphemeral,
+		EphemeralStorageRequestsQuantity: nodeRequestedEphemeral,
+		EphemeralStorageLimitsQuantity:   nodeLimitsEphemeral,
+		UsedEphemeralStorage:             "n/a",
+		EphemeralStorageUtilCapacity:     "n/a",
+		Pods:                             podMetricsOnNode,
+	}
+}
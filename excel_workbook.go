@@ -0,0 +1,410 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/jaegdi/cluster-resources/internal/history"
+)
+
+// historySheetWindow und historySheetSamplesPerSeries begrenzen, wie viel Zeitreihen-Historie das
+// "History"-Arbeitsblatt enthält: das letzte Fenster (statt der kompletten Store-Tiefe), damit die
+// Arbeitsmappe bei vielen Knoten nicht unbegrenzt wächst. Siehe writeHistorySheet.
+const (
+	historySheetWindow           = time.Hour
+	historySheetSamplesPerSeries = 50
+)
+
+// historySheetMetrics sind die Metriken, die pro Knoten ins "History"-Blatt geschrieben werden - eine
+// Teilmenge der von HistorySampler.sampleOnce gesampelten Metriken, auf die Auslastungsgrößen
+// beschränkt, die für eine Verlaufsbetrachtung interessant sind.
+var historySheetMetrics = []string{"used_cpu", "used_memory"}
+
+// buildClusterMetricsWorkbook baut die in downloadExcelHandler/excelRenderer (siehe renderers.go)
+// gemeinsam genutzte *excelize.File-Arbeitsmappe mit den Cluster-Metriken auf, ohne sie bereits zu
+// schreiben. Die Arbeitsmappe besteht aus vier Arbeitsblättern: "Cluster Summary" (aktuelle
+// Gesamtsummen), "Nodes" (aktuelle Werte je Knoten, mit bedingter Formatierung auf der
+// Auslastungsspalten), "History" (Zeitreihen aus historyStore) und "Labels" (Node→Label-Pivot), sowie
+// einem Diagramm-Blatt "Trends" (angefordert vs. genutzt je Knotentyp).
+//
+// historyStore darf nil sein; das "History"-Blatt enthält dann nur die Kopfzeile. main() übergibt
+// immer einen Store, auch ohne --history-checkpoint-path.
+func buildClusterMetricsWorkbook(clusterMetrics ClusterMetrics, historyStore *history.Store) (*excelize.File, error) {
+	file := excelize.NewFile()
+
+	if err := writeClusterSummarySheet(file, clusterMetrics); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error writing Cluster Summary sheet: %v", err)
+	}
+	if err := writeNodesSheet(file, clusterMetrics); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error writing Nodes sheet: %v", err)
+	}
+	if err := writeHistorySheet(file, clusterMetrics, historyStore); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error writing History sheet: %v", err)
+	}
+	if err := writeLabelsSheet(file, clusterMetrics); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error writing Labels sheet: %v", err)
+	}
+	if err := writeTrendsSheet(file, clusterMetrics); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error writing Trends sheet: %v", err)
+	}
+
+	if activeIndex, err := file.GetSheetIndex("Cluster Summary"); err == nil {
+		file.SetActiveSheet(activeIndex)
+	}
+
+	return file, nil
+}
+
+// writeClusterSummarySheet benennt das von excelize.NewFile angelegte Default-Blatt in
+// "Cluster Summary" um und füllt es mit den Gesamtsummen aus clusterMetrics als Metrik/Wert-Tabelle.
+// Das Blatt ist klein genug, um mit den normalen Set*-Methoden statt eines StreamWriters befüllt zu werden.
+func writeClusterSummarySheet(file *excelize.File, clusterMetrics ClusterMetrics) error {
+	const sheet = "Cluster Summary"
+	if err := file.SetSheetName(file.GetSheetName(0), sheet); err != nil {
+		return err
+	}
+
+	if err := file.SetCellValue(sheet, "A1", "Metric"); err != nil {
+		return err
+	}
+	if err := file.SetCellValue(sheet, "B1", "Value"); err != nil {
+		return err
+	}
+
+	rows := []struct {
+		label string
+		value float64
+	}{
+		{"Physical CPU (core)", quantityCPUCores(clusterMetrics.TotalPhysicalCPUQuantity)},
+		{"Requested CPU (core)", quantityCPUCores(clusterMetrics.TotalRequestedCPUQuantity)},
+		{"Limits CPU (core)", quantityCPUCores(clusterMetrics.TotalLimitsCPUQuantity)},
+		{"Used CPU (core)", quantityCPUCores(clusterMetrics.TotalUsedCPUQuantity)},
+		{"Physical Memory (Gi)", quantityMemGiB(clusterMetrics.TotalPhysicalMemoryQuantity)},
+		{"Requested Memory (Gi)", quantityMemGiB(clusterMetrics.TotalRequestedMemoryQuantity)},
+		{"Limits Memory (Gi)", quantityMemGiB(clusterMetrics.TotalLimitsMemoryQuantity)},
+		{"Used Memory (Gi)", quantityMemGiB(clusterMetrics.TotalUsedMemoryQuantity)},
+		{"Ephemeral Storage Capacity (Gi)", quantityMemGiB(clusterMetrics.TotalEphemeralStorageCapacityQuantity)},
+		{"Ephemeral Storage Requests (Gi)", quantityMemGiB(clusterMetrics.TotalEphemeralStorageRequestsQuantity)},
+		{"Ephemeral Storage Limits (Gi)", quantityMemGiB(clusterMetrics.TotalEphemeralStorageLimitsQuantity)},
+		{"Node Count", float64(len(clusterMetrics.Nodes))},
+	}
+	for i, row := range rows {
+		rowNum := i + 2
+		if err := file.SetCellValue(sheet, fmt.Sprintf("A%d", rowNum), row.label); err != nil {
+			return err
+		}
+		if err := file.SetCellValue(sheet, fmt.Sprintf("B%d", rowNum), row.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeNodesSheet schreibt ein Blatt "Nodes" mit einer Zeile je Knoten über einen excelize.StreamWriter,
+// statt die Arbeitsmappe komplett im Speicher aufzubauen, bevor sie nach /tmp geschrieben wird (so wie
+// es buildClusterMetricsWorkbook früher mit tealeg/xlsx tat). Statt der von printASCIITable/CSV
+// bekannten formatierten Auslastungsspalten (CPUUtilCapacity usw.) enthält das Blatt numerische
+// Prozent-Spalten ("CPU Util/Cap (%)", "Mem Util/Cap (%)"), auf die eine bedingte
+// Formatierung angewendet wird - Excel kann Schwellwerte nur auf numerische Zellen anwenden, nicht auf
+// vorformatierte Strings wie "45.2%".
+func writeNodesSheet(file *excelize.File, clusterMetrics ClusterMetrics) error {
+	const sheet = "Nodes"
+	if _, err := file.NewSheet(sheet); err != nil {
+		return err
+	}
+
+	sw, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+
+	header := []interface{}{
+		"Node", "Node Type",
+		"Physical CPU (core)", "Requested CPU (core)", "Limits CPU (core)", "Used CPU (core)",
+		"Physical Memory (Gi)", "Requested Memory (Gi)", "Limits Memory (Gi)", "Used Memory (Gi)",
+		"CPU Util/Cap (%)", "Mem Util/Cap (%)",
+		"Ephemeral Capacity (Gi)", "Ephemeral Requests (Gi)", "Ephemeral Limits (Gi)",
+	}
+	if err := sw.SetRow("A1", header); err != nil {
+		return err
+	}
+
+	for i, node := range clusterMetrics.Nodes {
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+		cpuUtilCap := utilizationPercentValue(node.UsedCPUQuantity, node.PhysicalCPUQuantity)
+		memUtilCap := utilizationPercentValue(node.UsedMemoryQuantity, node.PhysicalMemoryQuantity)
+		row := []interface{}{
+			node.Name, node.NodeType,
+			quantityCPUCores(node.PhysicalCPUQuantity), quantityCPUCores(node.RequestedCPUQuantity), quantityCPUCores(node.LimitsCPUQuantity), quantityCPUCores(node.UsedCPUQuantity),
+			quantityMemGiB(node.PhysicalMemoryQuantity), quantityMemGiB(node.RequestedMemoryQuantity), quantityMemGiB(node.LimitsMemoryQuantity), quantityMemGiB(node.UsedMemoryQuantity),
+			cpuUtilCap, memUtilCap,
+			quantityMemGiB(node.EphemeralStorageCapacityQuantity), quantityMemGiB(node.EphemeralStorageRequestsQuantity), quantityMemGiB(node.EphemeralStorageLimitsQuantity),
+		}
+		if err := sw.SetRow(cell, row); err != nil {
+			return err
+		}
+	}
+
+	if err := sw.Flush(); err != nil {
+		return err
+	}
+
+	if len(clusterMetrics.Nodes) == 0 {
+		return nil
+	}
+	lastRow := len(clusterMetrics.Nodes) + 1
+	if err := addUtilizationConditionalFormat(file, sheet, fmt.Sprintf("K2:K%d", lastRow)); err != nil {
+		return err
+	}
+	return addUtilizationConditionalFormat(file, sheet, fmt.Sprintf("L2:L%d", lastRow))
+}
+
+// utilizationPercentValue ist das numerische Gegenstück zu utilizationPercent: statt "n/a" oder
+// "45.2%" als String liefert es 0, wenn denom 0 ist (keine sinnvolle bedingte Formatierung möglich),
+// sonst das Verhältnis used/denom in Prozent als float64.
+func utilizationPercentValue(used, denom resource.Quantity) float64 {
+	if denom.IsZero() {
+		return 0
+	}
+	return float64(used.MilliValue()) / float64(denom.MilliValue()) * 100
+}
+
+// addUtilizationConditionalFormat markiert cellRange auf sheet grün (<70%), gelb (70-90%) oder rot
+// (>90%), passend zu den CPU/Mem-Util/Cap-Spalten aus writeNodesSheet (Auslastung gegenüber der
+// physischen Kapazität).
+func addUtilizationConditionalFormat(file *excelize.File, sheet, cellRange string) error {
+	green, err := file.NewConditionalStyle(&excelize.Style{
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#C6EFCE"}, Pattern: 1},
+		Font: &excelize.Font{Color: "#006100"},
+	})
+	if err != nil {
+		return err
+	}
+	yellow, err := file.NewConditionalStyle(&excelize.Style{
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#FFEB9C"}, Pattern: 1},
+		Font: &excelize.Font{Color: "#9C6500"},
+	})
+	if err != nil {
+		return err
+	}
+	red, err := file.NewConditionalStyle(&excelize.Style{
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#FFC7CE"}, Pattern: 1},
+		Font: &excelize.Font{Color: "#9C0006"},
+	})
+	if err != nil {
+		return err
+	}
+
+	return file.SetConditionalFormat(sheet, cellRange, []excelize.ConditionalFormatOptions{
+		{Type: "cell", Criteria: ">", Format: red, Value: "90"},
+		{Type: "cell", Criteria: "between", Format: yellow, MinValue: "70", MaxValue: "90"},
+		{Type: "cell", Criteria: "<", Format: green, Value: "70"},
+	})
+}
+
+// writeHistorySheet schreibt ein Blatt "History" mit den letzten historySheetSamplesPerSeries Samples
+// je Knoten und Metrik (siehe historySheetMetrics) aus dem letzten historySheetWindow, gelesen aus
+// historyStore. historyStore darf nil sein; das Blatt erhält dann nur die Kopfzeile. Ein Store ohne
+// Samples für eine Node/Metrik-Kombination (z.B. direkt nach dem Serverstart) liefert für diese
+// Kombination keine Zeilen.
+func writeHistorySheet(file *excelize.File, clusterMetrics ClusterMetrics, historyStore *history.Store) error {
+	const sheet = "History"
+	if _, err := file.NewSheet(sheet); err != nil {
+		return err
+	}
+
+	sw, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+
+	if err := sw.SetRow("A1", []interface{}{"Node", "Node Type", "Metric", "Timestamp", "Value"}); err != nil {
+		return err
+	}
+
+	rowNum := 2
+	if historyStore != nil {
+		to := time.Now()
+		from := to.Add(-historySheetWindow)
+		for _, node := range clusterMetrics.Nodes {
+			for _, metric := range historySheetMetrics {
+				samples, err := historyStore.Range(node.NodeType, node.Name, metric, from, to, 0)
+				if err != nil {
+					// Keine Historie für diese Node/Metrik-Kombination (z.B. weil der Sampler noch keinen
+					// Durchlauf hatte) ist kein Fehler, der den ganzen Export scheitern lassen soll.
+					continue
+				}
+				if len(samples) > historySheetSamplesPerSeries {
+					samples = samples[len(samples)-historySheetSamplesPerSeries:]
+				}
+				for _, sample := range samples {
+					cell, err := excelize.CoordinatesToCellName(1, rowNum)
+					if err != nil {
+						return err
+					}
+					row := []interface{}{node.Name, node.NodeType, metric, sample.Time.Format(time.RFC3339), sample.Value}
+					if err := sw.SetRow(cell, row); err != nil {
+						return err
+					}
+					rowNum++
+				}
+			}
+		}
+	}
+
+	return sw.Flush()
+}
+
+// writeLabelsSheet schreibt ein Blatt "Labels" als Pivot Node→Label-Schlüssel→Wert: eine Zeile je
+// Knoten, eine Spalte je Label-Schlüssel, der auf mindestens einem Knoten vorkommt. Fehlt ein Schlüssel
+// auf einem Knoten, bleibt die Zelle leer statt den Schlüssel wegzulassen, damit die Spaltenzuordnung
+// über alle Zeilen hinweg stabil bleibt.
+func writeLabelsSheet(file *excelize.File, clusterMetrics ClusterMetrics) error {
+	const sheet = "Labels"
+	if _, err := file.NewSheet(sheet); err != nil {
+		return err
+	}
+
+	keySet := make(map[string]struct{})
+	for _, node := range clusterMetrics.Nodes {
+		for key := range node.Labels {
+			keySet[key] = struct{}{}
+		}
+	}
+	keys := make([]string, 0, len(keySet))
+	for key := range keySet {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	if err := file.SetCellValue(sheet, "A1", "Node"); err != nil {
+		return err
+	}
+	for i, key := range keys {
+		cell, err := excelize.CoordinatesToCellName(i+2, 1)
+		if err != nil {
+			return err
+		}
+		if err := file.SetCellValue(sheet, cell, key); err != nil {
+			return err
+		}
+	}
+
+	for rowIdx, node := range clusterMetrics.Nodes {
+		rowNum := rowIdx + 2
+		if err := file.SetCellValue(sheet, fmt.Sprintf("A%d", rowNum), node.Name); err != nil {
+			return err
+		}
+		for colIdx, key := range keys {
+			cell, err := excelize.CoordinatesToCellName(colIdx+2, rowNum)
+			if err != nil {
+				return err
+			}
+			if err := file.SetCellValue(sheet, cell, node.Labels[key]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeTrendsSheet schreibt ein Blatt "Trends" mit einer kleinen Hilfstabelle (angeforderte vs.
+// genutzte CPU/Memory je Knotentyp, gemittelt über alle Knoten dieses Typs) und zwei Balkendiagrammen,
+// die diese Tabelle visualisieren.
+func writeTrendsSheet(file *excelize.File, clusterMetrics ClusterMetrics) error {
+	const sheet = "Trends"
+	if _, err := file.NewSheet(sheet); err != nil {
+		return err
+	}
+
+	type nodeTypeTotals struct {
+		requestedCPU, usedCPU, requestedMem, usedMem float64
+		count                                        int
+	}
+	totalsByType := make(map[string]*nodeTypeTotals)
+	var nodeTypeOrder []string
+	for _, node := range clusterMetrics.Nodes {
+		totals, ok := totalsByType[node.NodeType]
+		if !ok {
+			totals = &nodeTypeTotals{}
+			totalsByType[node.NodeType] = totals
+			nodeTypeOrder = append(nodeTypeOrder, node.NodeType)
+		}
+		totals.requestedCPU += quantityCPUCores(node.RequestedCPUQuantity)
+		totals.usedCPU += quantityCPUCores(node.UsedCPUQuantity)
+		totals.requestedMem += quantityMemGiB(node.RequestedMemoryQuantity)
+		totals.usedMem += quantityMemGiB(node.UsedMemoryQuantity)
+		totals.count++
+	}
+	sort.Strings(nodeTypeOrder)
+
+	header := []interface{}{"Node Type", "Requested CPU (core, avg)", "Used CPU (core, avg)", "Requested Memory (Gi, avg)", "Used Memory (Gi, avg)"}
+	for i, v := range header {
+		cell, err := excelize.CoordinatesToCellName(i+1, 1)
+		if err != nil {
+			return err
+		}
+		if err := file.SetCellValue(sheet, cell, v); err != nil {
+			return err
+		}
+	}
+
+	for i, nodeType := range nodeTypeOrder {
+		totals := totalsByType[nodeType]
+		rowNum := i + 2
+		row := []interface{}{
+			nodeType,
+			totals.requestedCPU / float64(totals.count),
+			totals.usedCPU / float64(totals.count),
+			totals.requestedMem / float64(totals.count),
+			totals.usedMem / float64(totals.count),
+		}
+		for colIdx, v := range row {
+			cell, err := excelize.CoordinatesToCellName(colIdx+1, rowNum)
+			if err != nil {
+				return err
+			}
+			if err := file.SetCellValue(sheet, cell, v); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(nodeTypeOrder) == 0 {
+		return nil
+	}
+	lastRow := len(nodeTypeOrder) + 1
+	categories := fmt.Sprintf("%s!$A$2:$A$%d", sheet, lastRow)
+
+	if err := file.AddChart(sheet, "G2", &excelize.Chart{
+		Type: excelize.Bar,
+		Series: []excelize.ChartSeries{
+			{Name: fmt.Sprintf("%s!$B$1", sheet), Categories: categories, Values: fmt.Sprintf("%s!$B$2:$B$%d", sheet, lastRow)},
+			{Name: fmt.Sprintf("%s!$C$1", sheet), Categories: categories, Values: fmt.Sprintf("%s!$C$2:$C$%d", sheet, lastRow)},
+		},
+		Title: []excelize.RichTextRun{{Text: "Requested vs. Used CPU per Node Type"}},
+	}); err != nil {
+		return err
+	}
+
+	return file.AddChart(sheet, "G18", &excelize.Chart{
+		Type: excelize.Bar,
+		Series: []excelize.ChartSeries{
+			{Name: fmt.Sprintf("%s!$D$1", sheet), Categories: categories, Values: fmt.Sprintf("%s!$D$2:$D$%d", sheet, lastRow)},
+			{Name: fmt.Sprintf("%s!$E$1", sheet), Categories: categories, Values: fmt.Sprintf("%s!$E$2:$E$%d", sheet, lastRow)},
+		},
+		Title: []excelize.RichTextRun{{Text: "Requested vs. Used Memory per Node Type"}},
+	})
+}
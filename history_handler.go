@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/jaegdi/cluster-resources/internal/history"
+)
+
+// HistorySampler sampelt periodisch eine Cluster-Metrics-Momentaufnahme (via snapshot) und schreibt
+// pro Node und Metrik einen Datenpunkt in den history.Store, damit /metrics/history Zeitreihen über
+// CPU/Memory-Auslastung liefern kann statt nur den aktuellen Snapshot.
+type HistorySampler struct {
+	store    *history.Store
+	interval time.Duration
+	snapshot func() (ClusterMetrics, error)
+}
+
+// NewHistorySampler erstellt einen HistorySampler, der alle interval snapshot() aufruft und das
+// Ergebnis in store schreibt. snapshot kapselt den Unterschied zwischen dem Watch-Cache- und dem
+// Direkt-Polling-Codepfad (siehe main()), damit der Sampler selbst nichts davon wissen muss.
+func NewHistorySampler(store *history.Store, interval time.Duration, snapshot func() (ClusterMetrics, error)) *HistorySampler {
+	return &HistorySampler{store: store, interval: interval, snapshot: snapshot}
+}
+
+// Run sampelt sofort einmal und danach im konfigurierten Intervall, bis ctx beendet wird. Ein Fehler
+// beim Snapshot wird geloggt; der nächste Tick versucht es erneut, statt die Sampler-Goroutine zu beenden.
+func (h *HistorySampler) Run(ctx context.Context) {
+	h.sampleOnce()
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.sampleOnce()
+		}
+	}
+}
+
+// sampleOnce holt eine ClusterMetrics-Momentaufnahme und trägt die CPU/Memory-Rohwerte jedes Knotens
+// in den Store ein. UsedCPU/UsedMemory werden ausgelassen, wenn für den Knoten kein metrics-server-
+// Sample vorlag (node.MetricsAvailable == false), damit "n/a" nicht als 0 in die Historie einfließt.
+func (h *HistorySampler) sampleOnce() {
+	metrics, err := h.snapshot()
+	if err != nil {
+		log.Printf("history sampler: failed to snapshot cluster metrics: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, node := range metrics.Nodes {
+		h.store.Record(node.NodeType, node.Name, "physical_cpu", now, quantityCPUCores(node.PhysicalCPUQuantity))
+		h.store.Record(node.NodeType, node.Name, "requested_cpu", now, quantityCPUCores(node.RequestedCPUQuantity))
+		h.store.Record(node.NodeType, node.Name, "limits_cpu", now, quantityCPUCores(node.LimitsCPUQuantity))
+		h.store.Record(node.NodeType, node.Name, "physical_memory", now, quantityMemGiB(node.PhysicalMemoryQuantity))
+		h.store.Record(node.NodeType, node.Name, "requested_memory", now, quantityMemGiB(node.RequestedMemoryQuantity))
+		h.store.Record(node.NodeType, node.Name, "limits_memory", now, quantityMemGiB(node.LimitsMemoryQuantity))
+		if node.MetricsAvailable {
+			h.store.Record(node.NodeType, node.Name, "used_cpu", now, quantityCPUCores(node.UsedCPUQuantity))
+			h.store.Record(node.NodeType, node.Name, "used_memory", now, quantityMemGiB(node.UsedMemoryQuantity))
+		}
+	}
+}
+
+// runCheckpointLoop ruft store.SaveCheckpoint alle interval auf, bis ctx beendet wird, und schreibt
+// einen letzten Checkpoint, sobald ctx beendet wird, damit Daten seit dem letzten Tick nicht verloren
+// gehen. Ist interval <= 0, kehrt die Funktion sofort zurück. Ohne --history-checkpoint-path läuft die
+// Schleife trotzdem, jeder SaveCheckpoint-Aufruf ist dann aber ein No-op.
+func runCheckpointLoop(ctx context.Context, store *history.Store, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := store.SaveCheckpoint(); err != nil {
+				log.Printf("history: failed to write final checkpoint: %v", err)
+			}
+			return
+		case <-ticker.C:
+			if err := store.SaveCheckpoint(); err != nil {
+				log.Printf("history: failed to write checkpoint: %v", err)
+			}
+		}
+	}
+}
+
+// historyQueryResult ist die JSON-Antwort von historyHandler, wenn kein "agg"-Parameter angegeben ist:
+// eine chronologisch aufsteigende Liste von (timestamp, value)-Paaren.
+type historyQueryResult struct {
+	Node       string           `json:"node"`
+	Metric     string           `json:"metric"`
+	From       time.Time        `json:"from"`
+	To         time.Time        `json:"to"`
+	Resolution string           `json:"resolution,omitempty"`
+	Samples    []history.Sample `json:"samples"`
+}
+
+// historyAggregateResult ist die JSON-Antwort von historyHandler, wenn "agg" angegeben ist: ein
+// einzelner aggregierter Wert über das angefragte Fenster statt der rohen Samples.
+type historyAggregateResult struct {
+	Node      string    `json:"node"`
+	Metric    string    `json:"metric"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	Aggregate string    `json:"aggregate"`
+	Value     float64   `json:"value"`
+	Samples   int       `json:"samples"`
+}
+
+// historyHandler baut den HTTP-Handler für /metrics/history, der Zeitreihen aus store liest.
+//
+// Query-Parameter:
+//   - node (required): Name des Knotens.
+//   - metric (required): z.B. "used_cpu", "physical_memory" (siehe HistorySampler.sampleOnce für die
+//     vollständige Liste der gesampelten Metriknamen).
+//   - node-type (optional, Default "all"): schränkt die Suche auf den node-type-Label ein, unter dem
+//     der Knoten gesampelt wurde (siehe --node-type). Der Default "all" durchsucht alle Node-Typen
+//     (siehe history.Store.Range), damit der dokumentierte einfache Aufruf ?node=X&metric=Y auch dann
+//     Treffer liefert, wenn der Aufrufer den Node-Typ des Knotens nicht kennt.
+//   - from, to (optional, RFC3339): Zeitfenster. Default: die letzte Stunde bis jetzt.
+//   - resolution (optional, Go-Duration wie "1m"): gewünschte Granularität; der Store wählt die
+//     passende Tier-Stufe (siehe history.Store.Range).
+//   - agg (optional): "avg", "min", "max" oder "p95" - liefert einen einzelnen aggregierten Wert
+//     statt der rohen Samples.
+//
+// Antworten sind bei Erfolg JSON (historyQueryResult oder historyAggregateResult), bei Fehlern ein
+// strukturiertes JSON-Fehlerobjekt (siehe writeJSONError in renderers.go) mit passendem HTTP-Statuscode.
+func historyHandler(store *history.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		node := r.URL.Query().Get("node")
+		metric := r.URL.Query().Get("metric")
+		if node == "" || metric == "" {
+			writeJSONError(w, http.StatusBadRequest, "query parameters 'node' and 'metric' are required")
+			return
+		}
+		nodeTypeParam := r.URL.Query().Get("node-type")
+		if nodeTypeParam == "" {
+			nodeTypeParam = "all"
+		}
+
+		to := time.Now()
+		if toParam := r.URL.Query().Get("to"); toParam != "" {
+			parsed, err := time.Parse(time.RFC3339, toParam)
+			if err != nil {
+				writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid 'to' parameter: %v", err))
+				return
+			}
+			to = parsed
+		}
+		from := to.Add(-time.Hour)
+		if fromParam := r.URL.Query().Get("from"); fromParam != "" {
+			parsed, err := time.Parse(time.RFC3339, fromParam)
+			if err != nil {
+				writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid 'from' parameter: %v", err))
+				return
+			}
+			from = parsed
+		}
+
+		var resolution time.Duration
+		if resParam := r.URL.Query().Get("resolution"); resParam != "" {
+			parsed, err := time.ParseDuration(resParam)
+			if err != nil {
+				writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid 'resolution' parameter: %v", err))
+				return
+			}
+			resolution = parsed
+		}
+
+		samples, err := store.Range(nodeTypeParam, node, metric, from, to, resolution)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("error reading history: %v", err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if agg := r.URL.Query().Get("agg"); agg != "" {
+			value, err := history.Aggregate(samples, agg)
+			if err != nil {
+				writeJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			json.NewEncoder(w).Encode(historyAggregateResult{
+				Node:      node,
+				Metric:    metric,
+				From:      from,
+				To:        to,
+				Aggregate: agg,
+				Value:     value,
+				Samples:   len(samples),
+			})
+			return
+		}
+
+		resolutionLabel := ""
+		if resolution > 0 {
+			resolutionLabel = resolution.String()
+		}
+		json.NewEncoder(w).Encode(historyQueryResult{
+			Node:       node,
+			Metric:     metric,
+			From:       from,
+			To:         to,
+			Resolution: resolutionLabel,
+			Samples:    samples,
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jaegdi/cluster-resources/internal/history"
+)
+
+// TestHistoryHandlerDefaultNodeTypeFindsSamples covers the documented default usage of /metrics/history
+// (?node=X&metric=Y, no node-type): HistorySampler.sampleOnce always records under the node's real
+// node type, never under the literal string "all", so the handler's "all" default must still find the
+// samples instead of silently returning zero of them.
+func TestHistoryHandlerDefaultNodeTypeFindsSamples(t *testing.T) {
+	store := history.NewStore(history.DefaultTiers(), "")
+	now := time.Now()
+	store.Record("worker", "node-a", "used_cpu", now, 2.0)
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics/history?node=node-a&metric=used_cpu", nil)
+	rec := httptest.NewRecorder()
+
+	historyHandler(store)(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var result historyQueryResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(result.Samples) != 1 || result.Samples[0].Value != 2.0 {
+		t.Fatalf("expected 1 sample with value 2.0 on the default node-type path, got %+v", result.Samples)
+	}
+}
+
+// TestHistoryHandlerRequiresNodeAndMetric covers the required-parameter validation that runs before
+// any node-type/range logic.
+func TestHistoryHandlerRequiresNodeAndMetric(t *testing.T) {
+	store := history.NewStore(history.DefaultTiers(), "")
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics/history", nil)
+	rec := httptest.NewRecorder()
+
+	historyHandler(store)(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for missing node/metric, got %d", rec.Code)
+	}
+}
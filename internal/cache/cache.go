@@ -0,0 +1,174 @@
+// Package cache stellt einen geteilten, Informer-basierten Cache für Nodes und Pods bereit, damit
+// calculateClusterMetrics nicht mehr bei jedem /metrics-Request erneut Nodes().List und Pods("").List
+// gegen den Kubernetes-API-Server ausführen muss.
+//
+// Die metrics.k8s.io-API unterstützt kein Watch, daher wird sie stattdessen über eine periodische
+// Goroutine abgefragt und das Ergebnis in einem von einem sync.RWMutex geschützten Store gehalten.
+package cache
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsv "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// ClusterCache hält die lokal gespiegelten Node- und Pod-Stores sowie den periodisch aktualisierten
+// Metrics-Snapshot. Ein Request liest ausschließlich aus diesen In-Memory-Stores, nicht mehr direkt
+// aus der Kubernetes-API.
+type ClusterCache struct {
+	nodeInformer cache.SharedIndexInformer
+	podInformer  cache.SharedIndexInformer
+	nodeLister   listersv1.NodeLister
+	podLister    listersv1.PodLister
+
+	metricsClient       *metricsv.Clientset
+	metricsPollInterval time.Duration
+
+	metricsMu    sync.RWMutex
+	nodeMetrics  map[string]*metricsv1beta1.NodeMetrics
+	podMetrics   map[string]*metricsv1beta1.PodMetrics // keyed by "namespace/name"
+	metricsReady bool
+}
+
+// NewClusterCache erstellt einen ClusterCache, der Node- und Pod-Informer mit dem angegebenen
+// Resync-Intervall betreibt und die metrics.k8s.io-API alle metricsPollInterval neu abfragt.
+//
+// Start muss aufgerufen werden, bevor die Lesemethoden verlässliche Daten liefern.
+func NewClusterCache(clientset *kubernetes.Clientset, metricsClient *metricsv.Clientset, resyncPeriod, metricsPollInterval time.Duration) *ClusterCache {
+	factory := informers.NewSharedInformerFactory(clientset, resyncPeriod)
+	nodeInformer := factory.Core().V1().Nodes().Informer()
+	podInformer := factory.Core().V1().Pods().Informer()
+
+	return &ClusterCache{
+		nodeInformer:        nodeInformer,
+		podInformer:         podInformer,
+		nodeLister:          factory.Core().V1().Nodes().Lister(),
+		podLister:           factory.Core().V1().Pods().Lister(),
+		metricsClient:       metricsClient,
+		metricsPollInterval: metricsPollInterval,
+		nodeMetrics:         make(map[string]*metricsv1beta1.NodeMetrics),
+		podMetrics:          make(map[string]*metricsv1beta1.PodMetrics),
+	}
+}
+
+// Start startet die Informer und die Metrics-Poll-Goroutine und blockiert, bis der initiale Cache-Sync
+// abgeschlossen ist (oder ctx abgebrochen wird). Die Informer und die Poll-Goroutine laufen weiter,
+// bis ctx beendet wird.
+func (c *ClusterCache) Start(ctx context.Context) error {
+	go c.nodeInformer.Run(ctx.Done())
+	go c.podInformer.Run(ctx.Done())
+
+	if !cache.WaitForCacheSync(ctx.Done(), c.nodeInformer.HasSynced, c.podInformer.HasSynced) {
+		return fmt.Errorf("cache: timed out waiting for node/pod informer sync")
+	}
+
+	// Erster Metrics-Poll synchron, damit direkt nach Start() konsistente Daten vorliegen.
+	c.pollMetrics(ctx)
+
+	go c.runMetricsPoller(ctx)
+
+	return nil
+}
+
+// runMetricsPoller fragt metrics.k8s.io im konfigurierten Intervall ab, bis ctx beendet wird.
+func (c *ClusterCache) runMetricsPoller(ctx context.Context) {
+	ticker := time.NewTicker(c.metricsPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.pollMetrics(ctx)
+		}
+	}
+}
+
+// pollMetrics listet NodeMetricses und PodMetricses einmalig und ersetzt den bestehenden Snapshot
+// atomar. Ein Fehler beim Poll verwirft den alten Snapshot nicht, sondern wird nur geloggt, damit ein
+// einzelner transienter metrics-server-Ausfall den Cache nicht leert.
+func (c *ClusterCache) pollMetrics(ctx context.Context) {
+	nodeMetricsList, err := c.metricsClient.MetricsV1beta1().NodeMetricses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Printf("cache: failed to poll node metrics: %v", err)
+		return
+	}
+	podMetricsList, err := c.metricsClient.MetricsV1beta1().PodMetricses("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Printf("cache: failed to poll pod metrics: %v", err)
+		return
+	}
+
+	nodeMetrics := make(map[string]*metricsv1beta1.NodeMetrics, len(nodeMetricsList.Items))
+	for i := range nodeMetricsList.Items {
+		nm := nodeMetricsList.Items[i]
+		nodeMetrics[nm.Name] = &nm
+	}
+	podMetrics := make(map[string]*metricsv1beta1.PodMetrics, len(podMetricsList.Items))
+	for i := range podMetricsList.Items {
+		pm := podMetricsList.Items[i]
+		podMetrics[pm.Namespace+"/"+pm.Name] = &pm
+	}
+
+	c.metricsMu.Lock()
+	c.nodeMetrics = nodeMetrics
+	c.podMetrics = podMetrics
+	c.metricsReady = true
+	c.metricsMu.Unlock()
+}
+
+// ListNodes gibt alle Nodes aus dem lokalen Indexer zurück.
+func (c *ClusterCache) ListNodes() ([]*v1.Node, error) {
+	return c.nodeLister.List(labels.Everything())
+}
+
+// ListPodsByNode gibt alle Pods zurück, deren spec.nodeName dem angegebenen Node entspricht.
+func (c *ClusterCache) ListPodsByNode(nodeName string) ([]*v1.Pod, error) {
+	all, err := c.podLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	pods := make([]*v1.Pod, 0, len(all))
+	for _, pod := range all {
+		if pod.Spec.NodeName == nodeName {
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
+
+// NodeMetrics gibt die zuletzt gepollten Nutzungsmetriken eines Nodes zurück.
+func (c *ClusterCache) NodeMetrics(name string) (*metricsv1beta1.NodeMetrics, bool) {
+	c.metricsMu.RLock()
+	defer c.metricsMu.RUnlock()
+	nm, ok := c.nodeMetrics[name]
+	return nm, ok
+}
+
+// PodMetrics gibt die zuletzt gepollten Nutzungsmetriken eines Pods zurück.
+func (c *ClusterCache) PodMetrics(namespace, name string) (*metricsv1beta1.PodMetrics, bool) {
+	c.metricsMu.RLock()
+	defer c.metricsMu.RUnlock()
+	pm, ok := c.podMetrics[namespace+"/"+name]
+	return pm, ok
+}
+
+// MetricsReady meldet, ob mindestens ein erfolgreicher Metrics-Poll stattgefunden hat.
+func (c *ClusterCache) MetricsReady() bool {
+	c.metricsMu.RLock()
+	defer c.metricsMu.RUnlock()
+	return c.metricsReady
+}
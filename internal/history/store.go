@@ -0,0 +1,392 @@
+// Package history stellt einen In-Process-Zeitreihenspeicher für Cluster-Metriken bereit, damit
+// /metrics/history (siehe history_handler.go im Hauptpaket) nicht nur den aktuellen Snapshot, sondern
+// auch den Verlauf einer Metrik über einen Zeitraum liefern kann.
+//
+// Der Store ist ein Baum cluster→nodeType→node→metric (die Cluster-Ebene entfällt, weil dieses Tool,
+// wie der Rest des Pakets, immer genau einen Cluster im Blick hat - siehe ClusterMetrics, die
+// ebenfalls kein Cluster-Namensfeld kennt). Pro Knoten/Metrik-Kombination hält eine series mehrere
+// ringBuffer-Instanzen, eine je Auflösungsstufe (Tier), sodass der Speicherverbrauch unabhängig von
+// der Laufzeit des Prozesses beschränkt bleibt und eine Bereichsabfrage nur über die für ihre
+// angefragte Auflösung zuständige Stufe laufen muss (O(Fenstergröße/Auflösung) statt O(alle jemals
+// gesammelten Samples)).
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Sample ist ein einzelner Messpunkt (Zeitstempel, Wert) einer Zeitreihe.
+type Sample struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// Tier beschreibt eine Auflösungsstufe: Samples werden auf Resolution gerundet und für Window in die
+// Vergangenheit aufbewahrt. Je gröber die Auflösung, desto weiter reicht das Fenster zurück, ohne dass
+// der Ringpuffer selbst größer wird (Window/Resolution bleibt über alle Tiers vergleichbar klein).
+type Tier struct {
+	Resolution time.Duration
+	Window     time.Duration
+}
+
+// DefaultTiers liefert die im Issue beschriebenen Standard-Auflösungsstufen: 10s-Auflösung für die
+// letzte Stunde, 1m-Auflösung für die letzten 24h, 10m-Auflösung für die letzten 30 Tage.
+func DefaultTiers() []Tier {
+	return []Tier{
+		{Resolution: 10 * time.Second, Window: time.Hour},
+		{Resolution: time.Minute, Window: 24 * time.Hour},
+		{Resolution: 10 * time.Minute, Window: 30 * 24 * time.Hour},
+	}
+}
+
+// ringBuffer hält für eine Auflösungsstufe eine feste Anzahl Samples (Window/Resolution) und
+// überschreibt beim Überlauf die ältesten Einträge. Mehrere Record-Aufrufe innerhalb desselben
+// Auflösungs-Buckets aktualisieren denselben Slot statt einen neuen zu belegen, damit ein häufiger
+// Sampler die grobe Stufe nicht vorzeitig volllaufen lässt.
+type ringBuffer struct {
+	resolution time.Duration
+	samples    []Sample // fester Länge, Rest ist Zero-Value bis befüllt
+	next       int      // Index, an dem das nächste neue (nicht aktualisierte) Sample landet
+	filled     int      // Anzahl belegter Slots, max. len(samples)
+}
+
+func newRingBuffer(resolution, window time.Duration) *ringBuffer {
+	capacity := int(window / resolution)
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &ringBuffer{resolution: resolution, samples: make([]Sample, capacity)}
+}
+
+// add fügt ein Sample ein. Fällt der Zeitstempel in denselben Bucket wie das zuletzt geschriebene
+// Sample, wird dessen Wert aktualisiert statt einen neuen Slot zu belegen.
+func (rb *ringBuffer) add(s Sample) {
+	bucket := s.Time.Truncate(rb.resolution)
+	s.Time = bucket
+
+	if rb.filled > 0 {
+		lastIdx := (rb.next - 1 + len(rb.samples)) % len(rb.samples)
+		if rb.samples[lastIdx].Time.Equal(bucket) {
+			rb.samples[lastIdx] = s
+			return
+		}
+	}
+
+	rb.samples[rb.next] = s
+	rb.next = (rb.next + 1) % len(rb.samples)
+	if rb.filled < len(rb.samples) {
+		rb.filled++
+	}
+}
+
+// rangeSince liefert alle gespeicherten Samples mit from <= Time <= to, chronologisch aufsteigend sortiert.
+func (rb *ringBuffer) rangeSince(from, to time.Time) []Sample {
+	result := make([]Sample, 0, rb.filled)
+	for i := 0; i < rb.filled; i++ {
+		s := rb.samples[i]
+		if s.Time.IsZero() {
+			continue
+		}
+		if (s.Time.Equal(from) || s.Time.After(from)) && (s.Time.Equal(to) || s.Time.Before(to)) {
+			result = append(result, s)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Time.Before(result[j].Time) })
+	return result
+}
+
+// series bündelt die ringBuffer-Instanzen aller Tiers einer einzelnen Node/Metrik-Kombination.
+type series struct {
+	buffers []*ringBuffer // index-gleich zu Store.tiers
+}
+
+// Store ist der Wurzelknoten des nodeType→node→metric-Baums. Er ist nebenläufigkeitssicher: Record
+// wird typischerweise von genau einer Sampler-Goroutine aufgerufen, Range dagegen von HTTP-Handlern
+// beliebig vieler gleichzeitiger Requests.
+type Store struct {
+	mu             sync.RWMutex
+	tiers          []Tier
+	data           map[string]map[string]map[string]*series // nodeType -> node -> metric -> series
+	checkpointPath string
+}
+
+// NewStore erstellt einen leeren Store mit den angegebenen Tiers. checkpointPath kann leer sein, um
+// Checkpointing zu deaktivieren (siehe SaveCheckpoint/LoadCheckpoint).
+func NewStore(tiers []Tier, checkpointPath string) *Store {
+	return &Store{
+		tiers:          tiers,
+		data:           make(map[string]map[string]map[string]*series),
+		checkpointPath: checkpointPath,
+	}
+}
+
+// Record trägt einen Messwert für (nodeType, node, metric) zum Zeitpunkt at in alle Tiers ein.
+func (s *Store) Record(nodeType, node, metric string, at time.Time, value float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ser := s.seriesLocked(nodeType, node, metric)
+	for _, rb := range ser.buffers {
+		rb.add(Sample{Time: at, Value: value})
+	}
+}
+
+// seriesLocked gibt die series für (nodeType, node, metric) zurück und legt sie samt ihren
+// Tier-Ringpuffern bei Bedarf an. Der Aufrufer muss s.mu bereits halten.
+func (s *Store) seriesLocked(nodeType, node, metric string) *series {
+	nodes, ok := s.data[nodeType]
+	if !ok {
+		nodes = make(map[string]map[string]*series)
+		s.data[nodeType] = nodes
+	}
+	metrics, ok := nodes[node]
+	if !ok {
+		metrics = make(map[string]*series)
+		nodes[node] = metrics
+	}
+	ser, ok := metrics[metric]
+	if !ok {
+		ser = &series{buffers: make([]*ringBuffer, len(s.tiers))}
+		for i, tier := range s.tiers {
+			ser.buffers[i] = newRingBuffer(tier.Resolution, tier.Window)
+		}
+		metrics[metric] = ser
+	}
+	return ser
+}
+
+// Range liefert die Samples für (nodeType, node, metric) im Zeitraum [from, to], gelesen aus der
+// gröbsten Tier-Stufe, deren Auflösung resolution noch erfüllt (Tier.Resolution <= resolution). Ist
+// resolution 0, wird die feinste verfügbare Stufe verwendet. Gibt es für die Kombination noch keine
+// Daten, ist das Ergebnis eine leere (nicht nil) Liste statt eines Fehlers.
+//
+// nodeType == "all" (oder "") durchsucht alle bekannten Node-Typen statt genau einen: Record speichert
+// ausschließlich unter dem tatsächlichen Node-Typ (z.B. "worker"), nie unter dem literalen String "all",
+// daher würde eine Anfrage mit nodeType == "all" sonst immer leer bleiben, obwohl das der dokumentierte
+// Default-Wert von node-type in historyHandler ist.
+func (s *Store) Range(nodeType, node, metric string, from, to time.Time, resolution time.Duration) ([]Sample, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tierIdx := s.tierIndexFor(resolution)
+
+	if nodeType == "" || nodeType == "all" {
+		var result []Sample
+		for _, nodes := range s.data {
+			metrics, ok := nodes[node]
+			if !ok {
+				continue
+			}
+			ser, ok := metrics[metric]
+			if !ok {
+				continue
+			}
+			result = append(result, ser.buffers[tierIdx].rangeSince(from, to)...)
+		}
+		sort.Slice(result, func(i, j int) bool { return result[i].Time.Before(result[j].Time) })
+		if result == nil {
+			result = []Sample{}
+		}
+		return result, nil
+	}
+
+	nodes, ok := s.data[nodeType]
+	if !ok {
+		return []Sample{}, nil
+	}
+	metrics, ok := nodes[node]
+	if !ok {
+		return []Sample{}, nil
+	}
+	ser, ok := metrics[metric]
+	if !ok {
+		return []Sample{}, nil
+	}
+
+	return ser.buffers[tierIdx].rangeSince(from, to), nil
+}
+
+// tierIndexFor wählt die gröbste Tier-Stufe, deren Auflösung <= resolution ist (die gröbste Stufe
+// also, die der Anfrage noch genügt), damit eine grob aufgelöste Anfrage nicht unnötig viele Samples
+// aus der feinsten Stufe zurückliefert. resolution <= 0 wählt immer die feinste Stufe (Tiers[0]).
+func (s *Store) tierIndexFor(resolution time.Duration) int {
+	best := 0
+	if resolution <= 0 {
+		return best
+	}
+	for i, tier := range s.tiers {
+		if tier.Resolution <= resolution {
+			best = i
+		}
+	}
+	return best
+}
+
+// Aggregate berechnet eine einzelne Kennzahl (avg, min, max oder p95) über samples. Ein leeres
+// samples liefert einen Fehler, damit der Aufrufer das von einem gültigen 0-Wert unterscheiden kann.
+func Aggregate(samples []Sample, fn string) (float64, error) {
+	if len(samples) == 0 {
+		return 0, fmt.Errorf("no samples in requested window")
+	}
+	switch fn {
+	case "avg":
+		var sum float64
+		for _, s := range samples {
+			sum += s.Value
+		}
+		return sum / float64(len(samples)), nil
+	case "min":
+		min := samples[0].Value
+		for _, s := range samples[1:] {
+			if s.Value < min {
+				min = s.Value
+			}
+		}
+		return min, nil
+	case "max":
+		max := samples[0].Value
+		for _, s := range samples[1:] {
+			if s.Value > max {
+				max = s.Value
+			}
+		}
+		return max, nil
+	case "p95":
+		values := make([]float64, len(samples))
+		for i, s := range samples {
+			values[i] = s.Value
+		}
+		sort.Float64s(values)
+		idx := int(float64(len(values)-1) * 0.95)
+		return values[idx], nil
+	default:
+		return 0, fmt.Errorf("unknown aggregation function %q (expected avg, min, max or p95)", fn)
+	}
+}
+
+// checkpointSeries ist die JSON-Zwischendarstellung einer series für Checkpoint-Dateien: ein Sample-
+// Slice je Tier, in derselben Reihenfolge wie Store.tiers.
+type checkpointSeries struct {
+	Tiers [][]Sample `json:"tiers"`
+}
+
+// checkpointFile ist das auf Disk geschriebene Gesamtformat einer Store-Checkpoint-Datei.
+type checkpointFile struct {
+	Tiers []Tier                                            `json:"tiers"`
+	Data  map[string]map[string]map[string]checkpointSeries `json:"data"`
+}
+
+// SaveCheckpoint schreibt den aktuellen Inhalt des Store atomar (write-then-rename) nach
+// checkpointPath, damit ein Prozess-Neustart nicht die gesamte Historie verliert. Ist checkpointPath
+// leer, ist SaveCheckpoint ein No-op.
+func (s *Store) SaveCheckpoint() error {
+	if s.checkpointPath == "" {
+		return nil
+	}
+
+	s.mu.RLock()
+	cf := checkpointFile{
+		Tiers: s.tiers,
+		Data:  make(map[string]map[string]map[string]checkpointSeries, len(s.data)),
+	}
+	for nodeType, nodes := range s.data {
+		cf.Data[nodeType] = make(map[string]map[string]checkpointSeries, len(nodes))
+		for node, metrics := range nodes {
+			cf.Data[nodeType][node] = make(map[string]checkpointSeries, len(metrics))
+			for metric, ser := range metrics {
+				cs := checkpointSeries{Tiers: make([][]Sample, len(ser.buffers))}
+				for i, rb := range ser.buffers {
+					cs.Tiers[i] = rb.rangeSince(time.Time{}, time.Now().Add(100*365*24*time.Hour))
+				}
+				cf.Data[nodeType][node][metric] = cs
+			}
+		}
+	}
+	s.mu.RUnlock()
+
+	tmpPath := s.checkpointPath + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("history: failed to create checkpoint temp file: %w", err)
+	}
+	if err := json.NewEncoder(f).Encode(cf); err != nil {
+		f.Close()
+		return fmt.Errorf("history: failed to encode checkpoint: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("history: failed to close checkpoint temp file: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.checkpointPath); err != nil {
+		return fmt.Errorf("history: failed to install checkpoint file: %w", err)
+	}
+	return nil
+}
+
+// LoadCheckpoint lädt eine zuvor mit SaveCheckpoint geschriebene Checkpoint-Datei in den Store. Ist
+// checkpointPath leer oder existiert die Datei noch nicht (erster Start), ist LoadCheckpoint ein
+// No-op statt eines Fehlers. Die Tiers der Checkpoint-Datei müssen exakt zu s.tiers passen, sonst wird
+// die Datei verworfen und geloggt statt den Store in einen inkonsistenten Zustand zu bringen.
+func (s *Store) LoadCheckpoint() error {
+	if s.checkpointPath == "" {
+		return nil
+	}
+	f, err := os.Open(s.checkpointPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("history: failed to open checkpoint file: %w", err)
+	}
+	defer f.Close()
+
+	var cf checkpointFile
+	if err := json.NewDecoder(f).Decode(&cf); err != nil {
+		return fmt.Errorf("history: failed to decode checkpoint file: %w", err)
+	}
+	if len(cf.Tiers) != len(s.tiers) {
+		return fmt.Errorf("history: checkpoint file has %d tier(s), store is configured with %d, ignoring checkpoint", len(cf.Tiers), len(s.tiers))
+	}
+	for i, tier := range cf.Tiers {
+		if tier != s.tiers[i] {
+			return fmt.Errorf("history: checkpoint tier %d (%+v) does not match configured tier (%+v), ignoring checkpoint", i, tier, s.tiers[i])
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for nodeType, nodes := range cf.Data {
+		for node, metrics := range nodes {
+			for metric, cs := range metrics {
+				ser := s.seriesLocked(nodeType, node, metric)
+				for i, samples := range cs.Tiers {
+					if i >= len(ser.buffers) {
+						break
+					}
+					for _, sample := range samples {
+						ser.buffers[i].add(sample)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// EnsureCheckpointDir erstellt das Verzeichnis von checkpointPath, falls es noch nicht existiert, damit
+// SaveCheckpoint nicht an einem fehlenden Elternverzeichnis scheitert.
+func EnsureCheckpointDir(checkpointPath string) error {
+	if checkpointPath == "" {
+		return nil
+	}
+	dir := filepath.Dir(checkpointPath)
+	if dir == "" || dir == "." {
+		return nil
+	}
+	return os.MkdirAll(dir, 0o755)
+}
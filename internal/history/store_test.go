@@ -0,0 +1,130 @@
+package history
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRingBufferAddOverwritesSameBucket(t *testing.T) {
+	rb := newRingBuffer(10*time.Second, time.Minute) // capacity 6
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	rb.add(Sample{Time: base, Value: 1})
+	rb.add(Sample{Time: base.Add(2 * time.Second), Value: 2}) // same 10s bucket as base
+
+	got := rb.rangeSince(base.Add(-time.Minute), base.Add(time.Minute))
+	if len(got) != 1 {
+		t.Fatalf("expected 1 sample after same-bucket update, got %d: %+v", len(got), got)
+	}
+	if got[0].Value != 2 {
+		t.Errorf("expected updated value 2, got %v", got[0].Value)
+	}
+}
+
+func TestRingBufferAddOverflowsOldestSlot(t *testing.T) {
+	rb := newRingBuffer(time.Second, 3*time.Second) // capacity 3
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 5; i++ {
+		rb.add(Sample{Time: base.Add(time.Duration(i) * time.Second), Value: float64(i)})
+	}
+
+	got := rb.rangeSince(base.Add(-time.Hour), base.Add(time.Hour))
+	if len(got) != 3 {
+		t.Fatalf("expected capacity-limited 3 samples, got %d: %+v", len(got), got)
+	}
+	for i, s := range got {
+		want := float64(i + 2) // oldest two (0, 1) overwritten, only 2,3,4 remain
+		if s.Value != want {
+			t.Errorf("sample %d: expected value %v, got %v", i, want, s.Value)
+		}
+	}
+}
+
+func TestRingBufferRangeSinceFiltersWindow(t *testing.T) {
+	rb := newRingBuffer(time.Second, time.Minute)
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		rb.add(Sample{Time: base.Add(time.Duration(i) * time.Second), Value: float64(i)})
+	}
+
+	got := rb.rangeSince(base.Add(time.Second), base.Add(3*time.Second))
+	if len(got) != 3 {
+		t.Fatalf("expected 3 samples in [1s,3s], got %d: %+v", len(got), got)
+	}
+}
+
+func TestStoreRangeExactNodeType(t *testing.T) {
+	s := NewStore(DefaultTiers(), "")
+	now := time.Now()
+	s.Record("worker", "node-a", "used_cpu", now, 1.5)
+
+	got, err := s.Range("worker", "node-a", "used_cpu", now.Add(-time.Hour), now.Add(time.Hour), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0].Value != 1.5 {
+		t.Fatalf("expected 1 sample with value 1.5, got %+v", got)
+	}
+
+	got, err = s.Range("master", "node-a", "used_cpu", now.Add(-time.Hour), now.Add(time.Hour), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("expected no samples for mismatched node type, got %+v", got)
+	}
+}
+
+// TestStoreRangeAllSearchesAcrossNodeTypes covers the documented default behaviour of historyHandler
+// (node-type defaults to "all"): Record always writes under the real node type, never under "all", so
+// Range must search across node types when asked for "all" instead of returning an empty result.
+func TestStoreRangeAllSearchesAcrossNodeTypes(t *testing.T) {
+	s := NewStore(DefaultTiers(), "")
+	now := time.Now()
+	s.Record("worker", "node-a", "used_cpu", now, 1.5)
+
+	got, err := s.Range("all", "node-a", "used_cpu", now.Add(-time.Hour), now.Add(time.Hour), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0].Value != 1.5 {
+		t.Fatalf("expected 1 sample with value 1.5 via node-type=all, got %+v", got)
+	}
+}
+
+func TestStoreRangeUnknownNodeReturnsEmptyNotError(t *testing.T) {
+	s := NewStore(DefaultTiers(), "")
+	now := time.Now()
+
+	got, err := s.Range("all", "does-not-exist", "used_cpu", now.Add(-time.Hour), now.Add(time.Hour), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got == nil || len(got) != 0 {
+		t.Fatalf("expected empty (non-nil) slice, got %+v", got)
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	samples := []Sample{{Value: 1}, {Value: 2}, {Value: 3}, {Value: 4}}
+
+	if v, err := Aggregate(samples, "avg"); err != nil || v != 2.5 {
+		t.Errorf("avg: got %v, %v, want 2.5, nil", v, err)
+	}
+	if v, err := Aggregate(samples, "min"); err != nil || v != 1 {
+		t.Errorf("min: got %v, %v, want 1, nil", v, err)
+	}
+	if v, err := Aggregate(samples, "max"); err != nil || v != 4 {
+		t.Errorf("max: got %v, %v, want 4, nil", v, err)
+	}
+	if _, err := Aggregate(samples, "p95"); err != nil {
+		t.Errorf("p95: unexpected error %v", err)
+	}
+	if _, err := Aggregate(nil, "avg"); err == nil {
+		t.Error("expected error for empty samples, got nil")
+	}
+	if _, err := Aggregate(samples, "bogus"); err == nil {
+		t.Error("expected error for unknown aggregation function, got nil")
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	metricsv "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// prometheusNodeLabels sind die Node-Labels, die als zusätzliche Prometheus-Label-Dimensionen
+// an die Gauges angehängt werden, sofern der Node sie gesetzt hat.
+var prometheusNodeLabels = []string{"topology.kubernetes.io/zone", "node.kubernetes.io/instance-type"}
+
+// MetricsExporter stellt die zuletzt berechneten Cluster-Metriken im Prometheus-Exposition-Format bereit.
+//
+// Der Exporter hält einen gecachten ClusterMetrics-Snapshot vor und erneuert ihn höchstens alle
+// ScrapeInterval, damit häufige Prometheus-Scrapes nicht bei jedem Request erneut
+// Nodes().List/Pods().List/MetricsV1beta1() gegen die Kubernetes-API ausführen.
+type MetricsExporter struct {
+	clientset     *kubernetes.Clientset
+	metricsClient *metricsv.Clientset
+	nodeType      string
+
+	scrapeInterval time.Duration
+
+	mu          sync.Mutex
+	cached      ClusterMetrics
+	lastScraped time.Time
+}
+
+// NewMetricsExporter erstellt einen MetricsExporter, der calculateClusterMetrics hinter einem
+// Cache mit dem angegebenen Scrape-Intervall kapselt.
+//
+// Parameter:
+// - clientset: Kubernetes-Clientset für Nodes().List/Pods().List.
+// - metricsClient: Metrics-Clientset für NodeMetricses/PodMetricses.
+// - nodeType: Der Node-Typ-Filter, der auf calculateClusterMetrics angewendet wird (z.B. "worker" oder "all").
+// - scrapeInterval: Mindestabstand zwischen zwei tatsächlichen Neuberechnungen.
+func NewMetricsExporter(clientset *kubernetes.Clientset, metricsClient *metricsv.Clientset, nodeType string, scrapeInterval time.Duration) *MetricsExporter {
+	return &MetricsExporter{
+		clientset:      clientset,
+		metricsClient:  metricsClient,
+		nodeType:       nodeType,
+		scrapeInterval: scrapeInterval,
+	}
+}
+
+// refresh berechnet clusterMetrics neu, falls der Cache älter als ScrapeInterval ist.
+//
+// Die Funktion listet die Nodes frisch, damit neu hinzugekommene oder entfernte Nodes im
+// nächsten Scrape sichtbar werden, und delegiert die eigentliche Metrikberechnung an
+// calculateClusterMetrics. Schlägt die Neuberechnung fehl (z.B. transienter API-Fehler), wird
+// der Fehler geloggt und der zuletzt erfolgreich berechnete Snapshot weiter ausgeliefert, statt
+// den Scrape mit einem leeren Ergebnis zu beantworten.
+func (e *MetricsExporter) refresh() ClusterMetrics {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if time.Since(e.lastScraped) < e.scrapeInterval && !e.lastScraped.IsZero() {
+		return e.cached
+	}
+
+	nodes, err := getNodes(e.clientset)
+	if err != nil {
+		log.Printf("metrics exporter: failed to list nodes, serving last cached snapshot: %v", err)
+		return e.cached
+	}
+	clusterMetrics, err := calculateClusterMetrics(e.clientset, e.metricsClient, nodes, e.nodeType)
+	if err != nil {
+		log.Printf("metrics exporter: failed to calculate cluster metrics, serving last cached snapshot: %v", err)
+		return e.cached
+	}
+	e.cached = clusterMetrics
+	e.lastScraped = time.Now()
+	return e.cached
+}
+
+// ServeHTTP implementiert http.Handler und schreibt die Cluster-Metriken im Prometheus-
+// Text-Expositionsformat (HELP/TYPE-Kommentare gefolgt von Gauge-Zeilen) in die Antwort.
+//
+// Jeder Node bekommt eigene Gauges für physische/angeforderte/begrenzte/genutzte CPU und
+// Speicher, jeweils gelabelt mit node, node_type sowie optional zone und instance_type.
+// Zusätzlich werden Cluster-weite Summen ohne Node-Label emittiert.
+func (e *MetricsExporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	metrics := e.refresh()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writeClusterMetricsPrometheus(w, metrics)
+}
+
+// writeClusterMetricsPrometheus schreibt clusterMetrics im Prometheus-Text-Expositionsformat
+// (HELP/TYPE-Kommentare gefolgt von Gauge-Zeilen) nach w. Wird sowohl von MetricsExporter.ServeHTTP
+// als auch vom prometheusRenderer (siehe renderers.go) für /metrics?format=prom verwendet.
+//
+// Jeder Node bekommt eigene Gauges für physische/angeforderte/begrenzte/genutzte CPU und Speicher,
+// jeweils gelabelt mit node, node_type sowie optional zone und instance_type. Zusätzlich werden
+// Cluster-weite Summen ohne Node-Label emittiert.
+func writeClusterMetricsPrometheus(w io.Writer, metrics ClusterMetrics) {
+	gauges := []struct {
+		name string
+		help string
+	}{
+		{"cluster_node_cpu_physical_cores", "Physical CPU capacity of the node in cores"},
+		{"cluster_node_cpu_requested", "Requested CPU of the node in cores"},
+		{"cluster_node_cpu_limits", "CPU limits of the node in cores"},
+		{"cluster_node_cpu_used", "Currently used CPU of the node in cores"},
+		{"cluster_node_memory_physical_gibibytes", "Physical memory capacity of the node in GiB"},
+		{"cluster_node_memory_requested_gibibytes", "Requested memory of the node in GiB"},
+		{"cluster_node_memory_limits_gibibytes", "Memory limits of the node in GiB"},
+		{"cluster_node_memory_used_gibibytes", "Currently used memory of the node in GiB"},
+	}
+
+	for _, g := range gauges {
+		fmt.Fprintf(w, "# HELP %s %s\n", g.name, g.help)
+		fmt.Fprintf(w, "# TYPE %s gauge\n", g.name)
+		for _, node := range metrics.Nodes {
+			labels := prometheusLabelsForNode(node)
+			switch g.name {
+			case "cluster_node_cpu_physical_cores":
+				writePrometheusGauge(w, g.name, labels, node.PhysicalCPU)
+			case "cluster_node_cpu_requested":
+				writePrometheusGauge(w, g.name, labels, node.RequestedCPU)
+			case "cluster_node_cpu_limits":
+				writePrometheusGauge(w, g.name, labels, node.LimitsCPU)
+			case "cluster_node_cpu_used":
+				writePrometheusGauge(w, g.name, labels, node.UsedCPU)
+			case "cluster_node_memory_physical_gibibytes":
+				writePrometheusGauge(w, g.name, labels, node.PhysicalMemory)
+			case "cluster_node_memory_requested_gibibytes":
+				writePrometheusGauge(w, g.name, labels, node.RequestedMemory)
+			case "cluster_node_memory_limits_gibibytes":
+				writePrometheusGauge(w, g.name, labels, node.LimitsMemory)
+			case "cluster_node_memory_used_gibibytes":
+				writePrometheusGauge(w, g.name, labels, node.UsedMemory)
+			}
+		}
+	}
+
+	fmt.Fprintln(w, "# HELP cluster_total_cpu_cores Cluster-wide totals for CPU in cores, labeled by metric kind")
+	fmt.Fprintln(w, "# TYPE cluster_total_cpu_cores gauge")
+	writePrometheusGauge(w, "cluster_total_cpu_cores", `kind="physical"`, metrics.TotalPhysicalCPU)
+	writePrometheusGauge(w, "cluster_total_cpu_cores", `kind="requested"`, metrics.TotalRequestedCPU)
+	writePrometheusGauge(w, "cluster_total_cpu_cores", `kind="limits"`, metrics.TotalLimitsCPU)
+	writePrometheusGauge(w, "cluster_total_cpu_cores", `kind="used"`, metrics.TotalUsedCPU)
+
+	fmt.Fprintln(w, "# HELP cluster_total_memory_gibibytes Cluster-wide totals for memory in GiB, labeled by metric kind")
+	fmt.Fprintln(w, "# TYPE cluster_total_memory_gibibytes gauge")
+	writePrometheusGauge(w, "cluster_total_memory_gibibytes", `kind="physical"`, metrics.TotalPhysicalMemory)
+	writePrometheusGauge(w, "cluster_total_memory_gibibytes", `kind="requested"`, metrics.TotalRequestedMemory)
+	writePrometheusGauge(w, "cluster_total_memory_gibibytes", `kind="limits"`, metrics.TotalLimitsMemory)
+	writePrometheusGauge(w, "cluster_total_memory_gibibytes", `kind="used"`, metrics.TotalUsedMemory)
+}
+
+// prometheusLabelsForNode baut den Label-Teil einer Prometheus-Zeile für einen Node zusammen,
+// bestehend aus node, node_type und den in prometheusNodeLabels ausgewählten Node-Labels.
+func prometheusLabelsForNode(node NodeMetrics) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `node="%s",node_type="%s"`, node.Name, node.NodeType)
+	for _, key := range prometheusNodeLabels {
+		if val, ok := node.Labels[key]; ok {
+			fmt.Fprintf(&b, `,%s="%s"`, prometheusLabelName(key), val)
+		}
+	}
+	return b.String()
+}
+
+// prometheusLabelName normalisiert einen Kubernetes-Label-Key (z.B. "node.kubernetes.io/instance-type")
+// zu einem gültigen Prometheus-Label-Namen (z.B. "instance_type").
+func prometheusLabelName(key string) string {
+	if idx := strings.LastIndex(key, "/"); idx != -1 {
+		key = key[idx+1:]
+	}
+	return strings.ReplaceAll(key, "-", "_")
+}
+
+// writePrometheusGauge schreibt eine einzelne Gauge-Zeile im Prometheus-Textformat. Werte, die
+// sich nicht als float64 parsen lassen (z.B. "n/a" bei fehlendem metrics-server), werden
+// stillschweigend ausgelassen statt die ganze Antwort zu zerstören.
+func writePrometheusGauge(w io.Writer, name, labels, rawValue string) {
+	value, err := strconv.ParseFloat(strings.TrimSuffix(rawValue, "Gi"), 64)
+	if err != nil {
+		log.Printf("Skipping metric %s{%s}: cannot parse value %q: %v", name, labels, rawValue, err)
+		return
+	}
+	fmt.Fprintf(w, "%s{%s} %g\n", name, labels, value)
+}
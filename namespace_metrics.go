@@ -0,0 +1,486 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// WorkloadMetrics enthält die aggregierten Requests/Limits eines einzelnen Workloads (Deployment,
+// StatefulSet oder DaemonSet). Im Gegensatz zu NodeMetrics gibt es hier keine Used*-Felder: der
+// metrics-server liefert Nutzung nur je Pod/Container, nicht vorab auf einen Workload aggregiert, und
+// eine solche Aggregation würde eine zusätzliche Pod→Workload-Zuordnung über OwnerReferences
+// voraussetzen, die dieses Tool (noch) nicht nachzieht.
+type WorkloadMetrics struct {
+	Namespace string // Der Namespace des Workloads
+	Name      string // Der Name des Workloads
+	Kind      string // "Deployment", "StatefulSet" oder "DaemonSet"
+	Replicas  int32  // Gewünschte Replikatanzahl (bei DaemonSets: Anzahl passender Knoten, siehe DaemonSetStatus.DesiredNumberScheduled)
+
+	RequestedCPU    string // Angeforderte CPU über alle Replikate des Workloads
+	RequestedMemory string // Angeforderter Speicher über alle Replikate des Workloads
+	LimitsCPU       string // CPU-Limits über alle Replikate des Workloads
+	LimitsMemory    string // Speicher-Limits über alle Replikate des Workloads
+
+	RequestedCPUQuantity    resource.Quantity
+	RequestedMemoryQuantity resource.Quantity
+	LimitsCPUQuantity       resource.Quantity
+	LimitsMemoryQuantity    resource.Quantity
+}
+
+// NamespaceMetrics enthält die über alle Workloads eines Namespace aufsummierten Requests/Limits.
+type NamespaceMetrics struct {
+	Namespace     string // Der Name des Namespace
+	WorkloadCount int    // Anzahl der in diesem Namespace gefundenen Deployments/StatefulSets/DaemonSets
+
+	RequestedCPU    string
+	RequestedMemory string
+	LimitsCPU       string
+	LimitsMemory    string
+
+	RequestedCPUQuantity    resource.Quantity
+	RequestedMemoryQuantity resource.Quantity
+	LimitsCPUQuantity       resource.Quantity
+	LimitsMemoryQuantity    resource.Quantity
+}
+
+// parseNamespaceList zerlegt den Wert von --namespaces in seine einzelnen, getrimmten Namensbestandteile.
+// Ein leerer Wert liefert eine leere Liste zurück, was calculateWorkloadMetrics als "alle Namespaces" interpretiert.
+func parseNamespaceList(namespaces string) []string {
+	if strings.TrimSpace(namespaces) == "" {
+		return nil
+	}
+	var result []string
+	for _, ns := range strings.Split(namespaces, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			result = append(result, ns)
+		}
+	}
+	return result
+}
+
+// calculateWorkloadMetrics berechnet WorkloadMetrics für alle Deployments, StatefulSets und DaemonSets
+// in den angegebenen Namespaces, damit Plattform-Teams sehen können, welcher Workload wie viel
+// Worker-Kapazität anfordert - eine Ebene oberhalb von NodeMetrics.
+//
+// Parameter:
+// - clientset: Kubernetes-Clientset.
+// - namespaces: Liste einzuschließender Namespaces; ist die Liste leer, werden alle Namespaces durchsucht.
+//
+// Rückgabewerte:
+//   - []WorkloadMetrics: eine Zeile je gefundenem Workload, sortiert nach Namespace und Name.
+//   - error: falls das Auflisten einer der drei Workload-Arten in einem Namespace fehlschlägt.
+func calculateWorkloadMetrics(clientset *kubernetes.Clientset, namespaces []string) ([]WorkloadMetrics, error) {
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	var workloads []WorkloadMetrics
+	for _, ns := range namespaces {
+		deployments, err := clientset.AppsV1().Deployments(ns).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("error listing deployments in namespace %q: %v", ns, err)
+		}
+		for _, d := range deployments.Items {
+			replicas := int32(1)
+			if d.Spec.Replicas != nil {
+				replicas = *d.Spec.Replicas
+			}
+			workloads = append(workloads, workloadMetricsFromPodSpec(d.Namespace, d.Name, "Deployment", replicas, d.Spec.Template.Spec))
+		}
+
+		statefulSets, err := clientset.AppsV1().StatefulSets(ns).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("error listing statefulsets in namespace %q: %v", ns, err)
+		}
+		for _, s := range statefulSets.Items {
+			replicas := int32(1)
+			if s.Spec.Replicas != nil {
+				replicas = *s.Spec.Replicas
+			}
+			workloads = append(workloads, workloadMetricsFromPodSpec(s.Namespace, s.Name, "StatefulSet", replicas, s.Spec.Template.Spec))
+		}
+
+		daemonSets, err := clientset.AppsV1().DaemonSets(ns).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("error listing daemonsets in namespace %q: %v", ns, err)
+		}
+		for _, ds := range daemonSets.Items {
+			workloads = append(workloads, workloadMetricsFromPodSpec(ds.Namespace, ds.Name, "DaemonSet", ds.Status.DesiredNumberScheduled, ds.Spec.Template.Spec))
+		}
+	}
+
+	sort.Slice(workloads, func(i, j int) bool {
+		if workloads[i].Namespace != workloads[j].Namespace {
+			return workloads[i].Namespace < workloads[j].Namespace
+		}
+		return workloads[i].Name < workloads[j].Name
+	})
+	return workloads, nil
+}
+
+// workloadMetricsFromPodSpec berechnet die Requests/Limits eines einzelnen Pod-Templates nach
+// derselben Logik wie k8s.io/kubectl/pkg/util/resource.PodRequestsAndLimits: Die Requests/Limits der
+// regulären Container werden summiert; die der Init-Container werden dagegen nicht summiert, sondern
+// je Ressource nur mit dem größten Init-Container-Wert verrechnet, weil Init-Container sequentiell statt
+// parallel zu den Haupt-Containern laufen. Ist danach ein Pod-Overhead gesetzt (z.B. durch eine
+// RuntimeClass), wird dieser addiert. Das Ergebnis wird anschließend mit replicas multipliziert, um von
+// "pro Pod" auf "über alle Replikate des Workloads" hochzurechnen.
+func workloadMetricsFromPodSpec(namespace, name, kind string, replicas int32, podSpec v1.PodSpec) WorkloadMetrics {
+	var containerRequestsCPU, containerRequestsMem, containerLimitsCPU, containerLimitsMem resource.Quantity
+	for _, c := range podSpec.Containers {
+		containerRequestsCPU.Add(c.Resources.Requests[v1.ResourceCPU])
+		containerRequestsMem.Add(c.Resources.Requests[v1.ResourceMemory])
+		containerLimitsCPU.Add(c.Resources.Limits[v1.ResourceCPU])
+		containerLimitsMem.Add(c.Resources.Limits[v1.ResourceMemory])
+	}
+
+	var initRequestsCPU, initRequestsMem, initLimitsCPU, initLimitsMem resource.Quantity
+	for _, c := range podSpec.InitContainers {
+		initRequestsCPU = maxQuantity(initRequestsCPU, c.Resources.Requests[v1.ResourceCPU])
+		initRequestsMem = maxQuantity(initRequestsMem, c.Resources.Requests[v1.ResourceMemory])
+		initLimitsCPU = maxQuantity(initLimitsCPU, c.Resources.Limits[v1.ResourceCPU])
+		initLimitsMem = maxQuantity(initLimitsMem, c.Resources.Limits[v1.ResourceMemory])
+	}
+
+	requestedCPU := maxQuantity(containerRequestsCPU, initRequestsCPU)
+	requestedMem := maxQuantity(containerRequestsMem, initRequestsMem)
+	limitsCPU := maxQuantity(containerLimitsCPU, initLimitsCPU)
+	limitsMem := maxQuantity(containerLimitsMem, initLimitsMem)
+
+	if podSpec.Overhead != nil {
+		requestedCPU.Add(podSpec.Overhead[v1.ResourceCPU])
+		requestedMem.Add(podSpec.Overhead[v1.ResourceMemory])
+		limitsCPU.Add(podSpec.Overhead[v1.ResourceCPU])
+		limitsMem.Add(podSpec.Overhead[v1.ResourceMemory])
+	}
+
+	requestedCPU = scaleQuantity(requestedCPU, int64(replicas))
+	requestedMem = scaleQuantity(requestedMem, int64(replicas))
+	limitsCPU = scaleQuantity(limitsCPU, int64(replicas))
+	limitsMem = scaleQuantity(limitsMem, int64(replicas))
+
+	return WorkloadMetrics{
+		Namespace:               namespace,
+		Name:                    name,
+		Kind:                    kind,
+		Replicas:                replicas,
+		RequestedCPU:            convertCpuStr(requestedCPU),
+		RequestedMemory:         convertMemStr(requestedMem),
+		LimitsCPU:               convertCpuStr(limitsCPU),
+		LimitsMemory:            convertMemStr(limitsMem),
+		RequestedCPUQuantity:    requestedCPU,
+		RequestedMemoryQuantity: requestedMem,
+		LimitsCPUQuantity:       limitsCPU,
+		LimitsMemoryQuantity:    limitsMem,
+	}
+}
+
+// maxQuantity gibt das punktweise Maximum zweier resource.Quantity zurück (siehe
+// workloadMetricsFromPodSpec: größter Init-Container-Wert je Ressource).
+func maxQuantity(a, b resource.Quantity) resource.Quantity {
+	if b.Cmp(a) > 0 {
+		return b
+	}
+	return a
+}
+
+// scaleQuantity multipliziert q mit replicas, um von "pro Pod" auf "über alle Replikate" hochzurechnen.
+// Die Multiplikation arbeitet auf MilliValue statt Value, damit CPU-Werte unter 1 Kern (z.B. "500m")
+// nicht vor der Multiplikation auf 0 oder 1 gerundet werden.
+func scaleQuantity(q resource.Quantity, replicas int64) resource.Quantity {
+	if replicas <= 0 {
+		return resource.Quantity{}
+	}
+	return *resource.NewMilliQuantity(q.MilliValue()*replicas, q.Format)
+}
+
+// calculateNamespaceMetrics fasst workloads je Namespace zu NamespaceMetrics zusammen, sortiert nach Namespace-Name.
+func calculateNamespaceMetrics(workloads []WorkloadMetrics) []NamespaceMetrics {
+	var order []string
+	byNamespace := make(map[string]*NamespaceMetrics)
+	for _, w := range workloads {
+		nm, ok := byNamespace[w.Namespace]
+		if !ok {
+			nm = &NamespaceMetrics{Namespace: w.Namespace}
+			byNamespace[w.Namespace] = nm
+			order = append(order, w.Namespace)
+		}
+		nm.WorkloadCount++
+		nm.RequestedCPUQuantity.Add(w.RequestedCPUQuantity)
+		nm.RequestedMemoryQuantity.Add(w.RequestedMemoryQuantity)
+		nm.LimitsCPUQuantity.Add(w.LimitsCPUQuantity)
+		nm.LimitsMemoryQuantity.Add(w.LimitsMemoryQuantity)
+	}
+	sort.Strings(order)
+
+	result := make([]NamespaceMetrics, 0, len(order))
+	for _, ns := range order {
+		nm := byNamespace[ns]
+		nm.RequestedCPU = convertCpuStr(nm.RequestedCPUQuantity)
+		nm.RequestedMemory = convertMemStr(nm.RequestedMemoryQuantity)
+		nm.LimitsCPU = convertCpuStr(nm.LimitsCPUQuantity)
+		nm.LimitsMemory = convertMemStr(nm.LimitsMemoryQuantity)
+		result = append(result, *nm)
+	}
+	return result
+}
+
+// namespacesHandler baut den HTTP-Handler für /metrics/namespaces. namespaces ist der Wert von
+// --namespaces (leer bedeutet alle Namespaces); clientset wird bei jedem Request neu abgefragt, analog
+// zum Nicht-Watch-Cache-Codepfad von /metrics (siehe main()).
+//
+// Wie /metrics wählt der "format"-Query-Parameter die Ausgabe, hier mit eigenen Werten: "json",
+// "xlsx" oder (Default) HTML.
+func namespacesHandler(clientset *kubernetes.Clientset, namespaces string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		workloads, err := calculateWorkloadMetrics(clientset, parseNamespaceList(namespaces))
+		if err != nil {
+			log.Printf("Error calculating workload metrics: %v", err)
+			writeJSONError(w, http.StatusInternalServerError, "Error calculating workload metrics")
+			return
+		}
+		serveNamespaceMetrics(w, r, calculateNamespaceMetrics(workloads))
+	}
+}
+
+// workloadsHandler baut den HTTP-Handler für /metrics/workloads, analog zu namespacesHandler.
+func workloadsHandler(clientset *kubernetes.Clientset, namespaces string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		workloads, err := calculateWorkloadMetrics(clientset, parseNamespaceList(namespaces))
+		if err != nil {
+			log.Printf("Error calculating workload metrics: %v", err)
+			writeJSONError(w, http.StatusInternalServerError, "Error calculating workload metrics")
+			return
+		}
+		serveWorkloadMetrics(w, r, workloads)
+	}
+}
+
+// serveNamespaceMetrics wählt anhand des "format"-Query-Parameters HTML, JSON oder Excel und schreibt
+// namespaceMetrics entsprechend nach w, analog zu serveClusterMetrics (siehe renderers.go).
+func serveNamespaceMetrics(w http.ResponseWriter, r *http.Request, namespaceMetrics []NamespaceMetrics) {
+	switch r.URL.Query().Get("format") {
+	case "json":
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(namespaceMetrics); err != nil {
+			log.Printf("Error rendering namespace metrics as JSON: %v", err)
+		}
+	case "xlsx":
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		w.Header().Set("Content-Disposition", "attachment; filename=namespace_metrics.xlsx")
+		if err := writeNamespaceMetricsExcel(w, namespaceMetrics); err != nil {
+			log.Printf("Error generating namespace metrics Excel file: %v", err)
+			writeJSONError(w, http.StatusInternalServerError, "Error generating Excel file")
+		}
+	default:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		renderNamespaceMetricsHTML(w, namespaceMetrics)
+	}
+}
+
+// serveWorkloadMetrics ist das Gegenstück zu serveNamespaceMetrics für /metrics/workloads.
+func serveWorkloadMetrics(w http.ResponseWriter, r *http.Request, workloadMetrics []WorkloadMetrics) {
+	switch r.URL.Query().Get("format") {
+	case "json":
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(workloadMetrics); err != nil {
+			log.Printf("Error rendering workload metrics as JSON: %v", err)
+		}
+	case "xlsx":
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		w.Header().Set("Content-Disposition", "attachment; filename=workload_metrics.xlsx")
+		if err := writeWorkloadMetricsExcel(w, workloadMetrics); err != nil {
+			log.Printf("Error generating workload metrics Excel file: %v", err)
+			writeJSONError(w, http.StatusInternalServerError, "Error generating Excel file")
+		}
+	default:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		renderWorkloadMetricsHTML(w, workloadMetrics)
+	}
+}
+
+// namespaceMetricsTemplate rendert eine Tabelle mit denselben CSS-Klassen wie die /metrics-Vorlage in
+// renderTemplate, damit sich /metrics/namespaces optisch in die übrigen HTML-Ansichten einfügt.
+var namespaceMetricsTemplate = template.Must(template.New("namespaceMetrics").Parse(`
+<!DOCTYPE html>
+<html>
+<head>
+    <title>Namespace Metrics</title>
+    <style>
+        .header-row { background-color: lightgray; font-weight: bold; }
+        .requested-metrics { background-color: #bddabd; }
+        .limited-metrics { background-color: #d4bbbb; }
+    </style>
+</head>
+<body>
+    <h1>Namespace Metrics</h1>
+    <table border="1">
+        <tr class="header-row">
+            <th>Namespace</th>
+            <th>Workloads</th>
+            <th class="requested-metrics">Requested CPU (core)</th>
+            <th class="requested-metrics">Requested Memory (Gi)</th>
+            <th class="limited-metrics">Limits CPU (core)</th>
+            <th class="limited-metrics">Limits Memory (Gi)</th>
+        </tr>
+        {{ range . }}
+        <tr>
+            <td>{{ .Namespace }}</td>
+            <td>{{ .WorkloadCount }}</td>
+            <td class="requested-metrics">{{ .RequestedCPU }}</td>
+            <td class="requested-metrics">{{ .RequestedMemory }}</td>
+            <td class="limited-metrics">{{ .LimitsCPU }}</td>
+            <td class="limited-metrics">{{ .LimitsMemory }}</td>
+        </tr>
+        {{ end }}
+    </table>
+</body>
+</html>
+`))
+
+// workloadMetricsTemplate ist das Gegenstück zu namespaceMetricsTemplate für /metrics/workloads.
+var workloadMetricsTemplate = template.Must(template.New("workloadMetrics").Parse(`
+<!DOCTYPE html>
+<html>
+<head>
+    <title>Workload Metrics</title>
+    <style>
+        .header-row { background-color: lightgray; font-weight: bold; }
+        .requested-metrics { background-color: #bddabd; }
+        .limited-metrics { background-color: #d4bbbb; }
+    </style>
+</head>
+<body>
+    <h1>Workload Metrics</h1>
+    <table border="1">
+        <tr class="header-row">
+            <th>Namespace</th>
+            <th>Name</th>
+            <th>Kind</th>
+            <th>Replicas</th>
+            <th class="requested-metrics">Requested CPU (core)</th>
+            <th class="requested-metrics">Requested Memory (Gi)</th>
+            <th class="limited-metrics">Limits CPU (core)</th>
+            <th class="limited-metrics">Limits Memory (Gi)</th>
+        </tr>
+        {{ range . }}
+        <tr>
+            <td>{{ .Namespace }}</td>
+            <td>{{ .Name }}</td>
+            <td>{{ .Kind }}</td>
+            <td>{{ .Replicas }}</td>
+            <td class="requested-metrics">{{ .RequestedCPU }}</td>
+            <td class="requested-metrics">{{ .RequestedMemory }}</td>
+            <td class="limited-metrics">{{ .LimitsCPU }}</td>
+            <td class="limited-metrics">{{ .LimitsMemory }}</td>
+        </tr>
+        {{ end }}
+    </table>
+</body>
+</html>
+`))
+
+func renderNamespaceMetricsHTML(w http.ResponseWriter, namespaceMetrics []NamespaceMetrics) {
+	if err := namespaceMetricsTemplate.Execute(w, namespaceMetrics); err != nil {
+		log.Printf("Error rendering namespace metrics template: %v", err)
+	}
+}
+
+func renderWorkloadMetricsHTML(w http.ResponseWriter, workloadMetrics []WorkloadMetrics) {
+	if err := workloadMetricsTemplate.Execute(w, workloadMetrics); err != nil {
+		log.Printf("Error rendering workload metrics template: %v", err)
+	}
+}
+
+// writeNamespaceMetricsExcel baut eine einblättrige Arbeitsmappe mit namespaceMetrics und schreibt sie
+// direkt nach w, im selben Stil wie buildClusterMetricsWorkbook (siehe excel_workbook.go): über einen
+// excelize.StreamWriter statt über eine temporäre Datei.
+func writeNamespaceMetricsExcel(w http.ResponseWriter, namespaceMetrics []NamespaceMetrics) error {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	const sheet = "Namespaces"
+	if err := file.SetSheetName(file.GetSheetName(0), sheet); err != nil {
+		return err
+	}
+
+	sw, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+	if err := sw.SetRow("A1", []interface{}{"Namespace", "Workloads", "Requested CPU (core)", "Requested Memory (Gi)", "Limits CPU (core)", "Limits Memory (Gi)"}); err != nil {
+		return err
+	}
+	for i, nm := range namespaceMetrics {
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+		row := []interface{}{
+			nm.Namespace, nm.WorkloadCount,
+			quantityCPUCores(nm.RequestedCPUQuantity), quantityMemGiB(nm.RequestedMemoryQuantity),
+			quantityCPUCores(nm.LimitsCPUQuantity), quantityMemGiB(nm.LimitsMemoryQuantity),
+		}
+		if err := sw.SetRow(cell, row); err != nil {
+			return err
+		}
+	}
+	if err := sw.Flush(); err != nil {
+		return err
+	}
+	return file.Write(w)
+}
+
+// writeWorkloadMetricsExcel ist das Gegenstück zu writeNamespaceMetricsExcel für /metrics/workloads.
+func writeWorkloadMetricsExcel(w http.ResponseWriter, workloadMetrics []WorkloadMetrics) error {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	const sheet = "Workloads"
+	if err := file.SetSheetName(file.GetSheetName(0), sheet); err != nil {
+		return err
+	}
+
+	sw, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+	if err := sw.SetRow("A1", []interface{}{"Namespace", "Name", "Kind", "Replicas", "Requested CPU (core)", "Requested Memory (Gi)", "Limits CPU (core)", "Limits Memory (Gi)"}); err != nil {
+		return err
+	}
+	for i, wm := range workloadMetrics {
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+		row := []interface{}{
+			wm.Namespace, wm.Name, wm.Kind, wm.Replicas,
+			quantityCPUCores(wm.RequestedCPUQuantity), quantityMemGiB(wm.RequestedMemoryQuantity),
+			quantityCPUCores(wm.LimitsCPUQuantity), quantityMemGiB(wm.LimitsMemoryQuantity),
+		}
+		if err := sw.SetRow(cell, row); err != nil {
+			return err
+		}
+	}
+	if err := sw.Flush(); err != nil {
+		return err
+	}
+	return file.Write(w)
+}
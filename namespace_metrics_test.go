@@ -0,0 +1,72 @@
+package main
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// TestWorkloadMetricsFromPodSpecInitContainerExceedsRegular covers the case where an init container
+// requests more than the sum of the regular containers: the init-container max must win instead of
+// being summed away, matching k8s.io/kubectl/pkg/util/resource.PodRequestsAndLimits.
+func TestWorkloadMetricsFromPodSpecInitContainerExceedsRegular(t *testing.T) {
+	podSpec := v1.PodSpec{
+		InitContainers: []v1.Container{
+			{Resources: v1.ResourceRequirements{Requests: v1.ResourceList{
+				v1.ResourceCPU: resource.MustParse("2"),
+			}}},
+		},
+		Containers: []v1.Container{
+			{Resources: v1.ResourceRequirements{Requests: v1.ResourceList{
+				v1.ResourceCPU: resource.MustParse("500m"),
+			}}},
+		},
+	}
+
+	w := workloadMetricsFromPodSpec("default", "app", "Deployment", 1, podSpec)
+
+	if w.RequestedCPU != "2.00" {
+		t.Fatalf("expected init container's larger request to win, got %q", w.RequestedCPU)
+	}
+}
+
+// TestWorkloadMetricsFromPodSpecAddsPodOverhead covers RuntimeClass-style pod overhead being added on
+// top of the regular/init-container max.
+func TestWorkloadMetricsFromPodSpecAddsPodOverhead(t *testing.T) {
+	podSpec := v1.PodSpec{
+		Containers: []v1.Container{
+			{Resources: v1.ResourceRequirements{Requests: v1.ResourceList{
+				v1.ResourceCPU: resource.MustParse("500m"),
+			}}},
+		},
+		Overhead: v1.ResourceList{
+			v1.ResourceCPU: resource.MustParse("100m"),
+		},
+	}
+
+	w := workloadMetricsFromPodSpec("default", "app", "Deployment", 1, podSpec)
+
+	if w.RequestedCPUQuantity.MilliValue() != 600 {
+		t.Fatalf("expected 500m container request + 100m overhead = 600m, got %dm", w.RequestedCPUQuantity.MilliValue())
+	}
+}
+
+// TestWorkloadMetricsFromPodSpecScalesSubCoreCPUAcrossReplicas covers scaleQuantity's MilliValue path:
+// a naive Value()-based multiplication would round "200m" up to 1 core before scaling and report
+// 5 cores instead of 1000m.
+func TestWorkloadMetricsFromPodSpecScalesSubCoreCPUAcrossReplicas(t *testing.T) {
+	podSpec := v1.PodSpec{
+		Containers: []v1.Container{
+			{Resources: v1.ResourceRequirements{Requests: v1.ResourceList{
+				v1.ResourceCPU: resource.MustParse("200m"),
+			}}},
+		},
+	}
+
+	w := workloadMetricsFromPodSpec("default", "app", "Deployment", 5, podSpec)
+
+	if w.RequestedCPUQuantity.MilliValue() != 1000 {
+		t.Fatalf("expected 200m * 5 replicas = 1000m, got %dm", w.RequestedCPUQuantity.MilliValue())
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/jaegdi/cluster-resources/internal/history"
+)
+
+// Renderer erzeugt eine Repräsentation von ClusterMetrics und schreibt sie nach w. Die konkreten
+// Implementierungen ersetzen die vormals fest verdrahteten Aufrufe von renderTemplate,
+// printASCIITable und buildClusterMetricsWorkbook hinter einer gemeinsamen Schnittstelle, damit das
+// /metrics-Handler-Setup in main() die Ausgabe über den "format"-Query-Parameter auswählen kann.
+type Renderer interface {
+	Render(w io.Writer, m ClusterMetrics) error
+}
+
+// htmlRenderer rendert ClusterMetrics als HTML-Tabelle (siehe renderTemplate).
+type htmlRenderer struct {
+	include includeOptions
+}
+
+func (r htmlRenderer) Render(w io.Writer, m ClusterMetrics) error {
+	hw, ok := w.(http.ResponseWriter)
+	if !ok {
+		return fmt.Errorf("html renderer requires an http.ResponseWriter")
+	}
+	return renderTemplate(hw, m, r.include)
+}
+
+// asciiRenderer rendert ClusterMetrics als ASCII-Tabelle (siehe printASCIITable), aber nach w statt
+// fest nach os.Stdout.
+type asciiRenderer struct {
+	include includeOptions
+}
+
+func (r asciiRenderer) Render(w io.Writer, m ClusterMetrics) error {
+	printASCIITableTo(w, m, r.include)
+	return nil
+}
+
+// jsonRenderer rendert ClusterMetrics als eingerücktes JSON-Dokument mit allen Feldern, unabhängig
+// von includeOptions - ein Skript, das die Felder braucht, soll sie nicht erst per Query-Parameter
+// anfordern müssen.
+type jsonRenderer struct{}
+
+func (jsonRenderer) Render(w io.Writer, m ClusterMetrics) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
+
+// yamlRenderer rendert ClusterMetrics als YAML-Dokument.
+type yamlRenderer struct{}
+
+func (yamlRenderer) Render(w io.Writer, m ClusterMetrics) error {
+	out, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// csvRenderer rendert ClusterMetrics als CSV-Tabelle, eine Zeile je Node plus eine Total-Zeile,
+// mit denselben Spalten wie printASCIITable.
+type csvRenderer struct {
+	include includeOptions
+}
+
+func (r csvRenderer) Render(w io.Writer, m ClusterMetrics) error {
+	cw := csv.NewWriter(w)
+	defer cw.Flush()
+
+	header := []string{"Node", "Node Type", "Physical CPU", "Requested CPU", "Limits CPU", "Used CPU", "Physical Memory", "Requested Memory", "Limits Memory", "Used Memory", "CPU Util/Cap", "CPU Util/Req", "CPU Util/Limit", "Mem Util/Cap", "Mem Util/Req", "Mem Util/Limit"}
+	if r.include.Ephemeral {
+		header = append(header, "Ephemeral Capacity", "Ephemeral Requests", "Ephemeral Limits", "Ephemeral Used", "Ephemeral Util/Cap")
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, node := range m.Nodes {
+		row := []string{node.Name, node.NodeType, node.PhysicalCPU, node.RequestedCPU, node.LimitsCPU, node.UsedCPU, node.PhysicalMemory, node.RequestedMemory, node.LimitsMemory, node.UsedMemory, node.CPUUtilCapacity, node.CPUUtilRequests, node.CPUUtilLimits, node.MemUtilCapacity, node.MemUtilRequests, node.MemUtilLimits}
+		if r.include.Ephemeral {
+			row = append(row, node.EphemeralStorageCapacity, node.EphemeralStorageRequests, node.EphemeralStorageLimits, node.UsedEphemeralStorage, node.EphemeralStorageUtilCapacity)
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	totalRow := []string{"Total", "", m.TotalPhysicalCPU, m.TotalRequestedCPU, m.TotalLimitsCPU, m.TotalUsedCPU, m.TotalPhysicalMemory, m.TotalRequestedMemory, m.TotalLimitsMemory, m.TotalUsedMemory, "", "", "", "", "", ""}
+	if r.include.Ephemeral {
+		totalRow = append(totalRow, m.TotalEphemeralStorageCapacity, m.TotalEphemeralStorageRequests, m.TotalEphemeralStorageLimits, "", "")
+	}
+	return cw.Write(totalRow)
+}
+
+// prometheusRenderer rendert ClusterMetrics im Prometheus-Text-Expositionsformat. Nutzt dieselbe
+// Gauge-Definition wie der dedizierte /prometheus-Endpunkt (siehe metrics_exporter.go), liest die
+// Werte aber direkt aus der übergebenen ClusterMetrics statt aus dem MetricsExporter-Cache.
+type prometheusRenderer struct{}
+
+func (prometheusRenderer) Render(w io.Writer, m ClusterMetrics) error {
+	writeClusterMetricsPrometheus(w, m)
+	return nil
+}
+
+// excelRenderer rendert ClusterMetrics als Excel-Arbeitsmappe (siehe buildClusterMetricsWorkbook in
+// excel_workbook.go). Wird von downloadExcelHandler verwendet; nicht über den "format"-Query-Parameter
+// von /metrics erreichbar, da ein Download einen eigenen Content-Disposition-Header benötigt (siehe
+// downloadExcelHandler). store liefert die Zeitreihen für das "History"-Arbeitsblatt und darf nil sein.
+type excelRenderer struct {
+	store *history.Store
+}
+
+func (r excelRenderer) Render(w io.Writer, m ClusterMetrics) error {
+	file, err := buildClusterMetricsWorkbook(m, r.store)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return file.Write(w)
+}
+
+// serveClusterMetrics wählt anhand des "format"-Query-Parameters von r den passenden Renderer,
+// setzt den Content-Type-Header und schreibt m in w. Ohne "format"-Wert wird die Tabelle zusätzlich
+// als ASCII-Tabelle auf os.Stdout gedruckt, wie es der bisherige /metrics-Handler ohne format-Parameter
+// schon tat; bei allen übrigen Werten (auch einem unbekannten, der auf HTML zurückfällt) entfällt das, damit
+// z.B. ein Prometheus-Scraper im Sekundentakt nicht die Server-Logs flutet.
+func serveClusterMetrics(w http.ResponseWriter, r *http.Request, m ClusterMetrics, include includeOptions) {
+	format := r.URL.Query().Get("format")
+	renderer, contentType := rendererForFormat(format, include)
+	w.Header().Set("Content-Type", contentType)
+	if err := renderer.Render(w, m); err != nil {
+		log.Printf("Error rendering cluster metrics as %q: %v", format, err)
+		writeJSONError(w, http.StatusInternalServerError, "Error rendering cluster metrics")
+		return
+	}
+	if format == "" {
+		printASCIITable(m, include)
+	}
+}
+
+// writeJSONError schreibt einen strukturierten HTTP-Fehler als JSON-Objekt ({"error": "..."}) statt
+// als Klartext, damit Skripte/Dashboards, die /metrics oder /download/excel konsumieren, Fehler
+// maschinell auswerten können statt den Response-Body als Freitext parsen zu müssen.
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// rendererForFormat wählt anhand des "format"-Query-Parameter-Werts den passenden Renderer und den
+// dazugehörigen Content-Type. Ein unbekannter oder leerer format-Wert liefert den HTML-Renderer, der
+// das bisherige Standardverhalten von /metrics beibehält.
+func rendererForFormat(format string, include includeOptions) (renderer Renderer, contentType string) {
+	switch format {
+	case "json":
+		return jsonRenderer{}, "application/json"
+	case "yaml":
+		return yamlRenderer{}, "application/x-yaml"
+	case "csv":
+		return csvRenderer{include: include}, "text/csv"
+	case "prom", "prometheus":
+		return prometheusRenderer{}, "text/plain; version=0.0.4; charset=utf-8"
+	case "ascii", "text":
+		return asciiRenderer{include: include}, "text/plain; charset=utf-8"
+	default:
+		return htmlRenderer{include: include}, "text/html; charset=utf-8"
+	}
+}